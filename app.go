@@ -0,0 +1,148 @@
+package scaffold
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/launchrctl/launchr"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	templatesAppDir     = "app"     // Application project skeleton, e.g. go.mod and plugins.go
+	templatesProjectDir = "project" // Files shared by project skeletons, e.g. the Makefile
+)
+
+// appNamePlaceholder is replaced with the application or plugin name in paths of project templates
+const appNamePlaceholder = "__name__"
+
+// defaultLaunchrVersion is required by generated applications when the launchr version is unknown
+const defaultLaunchrVersion = "v0.21.2"
+
+// defaultGoVersion is set in go.mod of generated applications when the Go version is unknown
+const defaultGoVersion = "1.24"
+
+var appNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+// appValues are values of the application skeleton templates
+type appValues struct {
+	Name           string // Application name, also used for cmd/<name>
+	Binary         string // Name of the built binary, also used for the version info
+	Module         string // Go module path
+	GoVersion      string
+	LaunchrVersion string
+}
+
+// newAppValues validates the application name and module path, the module path defaults to the name
+func newAppValues(name, modulePath, launchrVersion string) (*appValues, error) {
+	if !appNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid application name '%s': it must start with a letter and contain only letters, digits, '-' and '_'", name)
+	}
+
+	values := &appValues{
+		Name:           name,
+		Binary:         name,
+		Module:         cmp.Or(modulePath, name),
+		GoVersion:      goVersion(),
+		LaunchrVersion: cmp.Or(launchrVersion, builtLaunchrVersion()),
+	}
+
+	err := module.CheckImportPath(values.Module)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module path: %w", err)
+	}
+	if !semver.IsValid(values.LaunchrVersion) {
+		return nil, fmt.Errorf("invalid launchr version '%s', expected a semantic version like %s", values.LaunchrVersion, defaultLaunchrVersion)
+	}
+
+	return values, nil
+}
+
+// builtLaunchrVersion returns the launchr version the running application is built with
+func builtLaunchrVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return defaultLaunchrVersion
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == launchrImportPath && semver.IsValid(dep.Version) {
+			return dep.Version
+		}
+	}
+
+	return defaultLaunchrVersion
+}
+
+// goVersion returns the major and minor version of Go the running application is built with
+func goVersion() string {
+	v := "v" + strings.TrimPrefix(runtime.Version(), "go")
+	if !semver.IsValid(v) {
+		// Development builds of Go.
+		return defaultGoVersion
+	}
+
+	return strings.TrimPrefix(semver.MajorMinor(v), "v")
+}
+
+// renderProject renders a project skeleton from a directory of the templates root in memory,
+// the name replaces [appNamePlaceholder] in paths
+func (t *templateManager) renderProject(dir, name string, data any) ([]*renderedFile, error) {
+	projectFS, err := fs.Sub(t.fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	sharedFS, err := fs.Sub(t.fs, templatesProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files of the skeleton override files shared by all skeletons.
+	files, err := t.renderTree(newLayeredFS([]templateSource{
+		{origin: dir, fs: projectFS},
+		{origin: templatesProjectDir, fs: sharedFS},
+	}), data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		f.path = strings.ReplaceAll(f.path, appNamePlaceholder, name)
+	}
+
+	return files, nil
+}
+
+// generateProject writes a rendered project skeleton to the directory
+func (g *generator) generateProject(kind, name, dir string, files []*renderedFile) error {
+	w, err := g.writeOutput(dir, files)
+	if err != nil || w == nil {
+		return err
+	}
+
+	launchr.Term().Success().Printfln("%s %s successfully generated in %s", kind, name, dir)
+	launchr.Term().Info().Printfln("Fetch dependencies and build it with: cd %s && go mod tidy && make build", filepath.Clean(dir))
+	return nil
+}
+
+// renderApp renders the application skeleton in memory
+func (t *templateManager) renderApp(values *appValues) ([]*renderedFile, error) {
+	return t.renderProject(templatesAppDir, values.Name, values)
+}
+
+// generateApp writes the application skeleton to the directory
+func (g *generator) generateApp(dir string, values *appValues) error {
+	files, err := g.tmplManager.renderApp(values)
+	if err != nil {
+		return err
+	}
+
+	return g.generateProject("Application", values.Name, dir, files)
+}
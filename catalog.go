@@ -0,0 +1,162 @@
+package scaffold
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/launchrctl/launchr"
+	"github.com/launchrctl/launchr/pkg/action"
+)
+
+// templateInfo describes a runtime or preset in templates listing
+type templateInfo struct {
+	Runtime     action.DefRuntimeType `json:"runtime"`
+	Preset      string                `json:"preset,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Origin      string                `json:"origin"`
+	Version     string                `json:"version"`
+	Vars        []string              `json:"vars,omitempty"`
+	Files       []*templateFileInfo   `json:"files"`
+}
+
+// templateFileInfo describes a file rendered into the action directory
+type templateFileInfo struct {
+	Path   string `json:"path"`
+	Origin string `json:"origin"`
+}
+
+// catalog describes every runtime and preset available in templates
+func (t *templateManager) catalog() ([]*templateInfo, error) {
+	var res []*templateInfo
+	for _, rt := range specRuntimes {
+		var variants []*Preset
+		// The container runtime always requires a preset, others have default files.
+		if rt != runtimeContainer {
+			variants = append(variants, nil)
+		}
+		variants = append(variants, t.presets.forRuntime(rt)...)
+
+		for _, p := range variants {
+			info, err := t.describe(rt, p)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, info)
+		}
+	}
+
+	return res, nil
+}
+
+// describe collects information about the runtime default files or a preset
+func (t *templateManager) describe(rt action.DefRuntimeType, p *Preset) (*templateInfo, error) {
+	values := &templateValues{
+		Definition: &action.Definition{
+			Runtime: &action.DefRuntime{Type: rt},
+		},
+	}
+	info := &templateInfo{
+		Runtime: rt,
+		Origin:  "embedded",
+		Version: templatesVersion,
+	}
+	if p != nil {
+		values.ContainerPreset = p.Name
+		info.Preset = p.Name
+		info.Description = p.Description
+		info.Origin = p.origin
+		info.Version = cmp.Or(p.Version, info.Version)
+	}
+
+	dir, _, err := t.getFilesDir(values)
+	if err != nil {
+		return nil, err
+	}
+	filesFS, err := t.getFilesFS(values)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := t.getPresetManifest(filesFS)
+	if err != nil {
+		return nil, err
+	}
+	info.Description = cmp.Or(manifest.Description, info.Description)
+	info.Version = cmp.Or(manifest.Version, info.Version)
+	for _, v := range manifest.Vars {
+		info.Vars = append(info.Vars, v.Name)
+	}
+
+	defOrigin := cmp.Or(t.definitionOverride(rt), "generated")
+	info.Files = append(info.Files, &templateFileInfo{Path: "action.yaml", Origin: defOrigin})
+
+	dirs, err := t.getTemplateSubdirectories(filesFS, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		matches, err := fs.Glob(filesFS, path.Join(d, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range matches {
+			origin := t.origin(path.Join(dir, m))
+			if origin == "" && p != nil {
+				// Not overridden in templates directories, comes from the contributed preset.
+				origin = p.origin
+			}
+			info.Files = append(info.Files, &templateFileInfo{Path: outputName(m), Origin: origin})
+		}
+	}
+
+	slices.SortFunc(info.Files, func(a, b *templateFileInfo) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return info, nil
+}
+
+// printCatalog prints templates listing in a human-readable format
+func printCatalog(entries []*templateInfo) {
+	for _, info := range entries {
+		name := string(info.Runtime)
+		if info.Preset != "" {
+			name = fmt.Sprintf("%s (preset %s)", info.Runtime, info.Preset)
+		}
+
+		launchr.Term().Info().Printfln("%s", name)
+		if info.Description != "" {
+			launchr.Term().Printfln("  %s", info.Description)
+		}
+		launchr.Term().Printfln("  origin: %s, version: %s", info.Origin, info.Version)
+		if len(info.Vars) > 0 {
+			launchr.Term().Printfln("  variables: %s", strings.Join(info.Vars, ", "))
+		}
+
+		paths := make([]string, 0, len(info.Files))
+		for _, f := range info.Files {
+			paths = append(paths, f.Path)
+		}
+		launchr.Term().Print(formatFileTree("<action dir>", paths))
+
+		for _, f := range info.Files {
+			if f.Origin != info.Origin {
+				launchr.Term().Printfln("  %s is provided by %s", f.Path, f.Origin)
+			}
+		}
+		launchr.Term().Println()
+	}
+}
+
+// printCatalogJSON prints templates listing as JSON
+func printCatalogJSON(w io.Writer, entries []*templateInfo) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
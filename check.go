@@ -0,0 +1,325 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/launchrctl/launchr"
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// checkCase is a set of synthetic values templates are rendered against
+type checkCase struct {
+	name   string
+	values func(values *templateValues)
+}
+
+// templateChecks is a matrix of values covering different shapes of a definition
+var templateChecks = []checkCase{
+	{
+		name:   "no params",
+		values: func(_ *templateValues) {},
+	},
+	{
+		name: "scalar params",
+		values: func(v *templateValues) {
+			v.Action.Arguments = action.ParametersList{
+				{Name: "arg_string", Title: "String", Type: jsonschema.String, Required: true},
+			}
+			v.Action.Options = action.ParametersList{
+				{Name: "opt_string", Type: jsonschema.String, Default: "value"},
+				{Name: "opt_integer", Type: jsonschema.Integer, Default: 3},
+				{Name: "opt_number", Type: jsonschema.Number, Default: 0.5},
+				{Name: "opt_boolean", Type: jsonschema.Boolean, Default: true},
+			}
+		},
+	},
+	{
+		name: "array params",
+		values: func(v *templateValues) {
+			v.Action.Arguments = action.ParametersList{
+				{Name: "arg_array", Type: jsonschema.Array, Items: &action.DefArrayItems{Type: jsonschema.String}, Default: []any{"a", "b"}},
+			}
+			v.Action.Options = action.ParametersList{
+				{Name: "opt_array", Type: jsonschema.Array, Items: &action.DefArrayItems{Type: jsonschema.Integer}, Default: []any{1, 2}},
+				{Name: "opt_array_empty", Type: jsonschema.Array, Items: &action.DefArrayItems{Type: jsonschema.String}, Default: []any{}},
+			}
+		},
+	},
+	{
+		name: "constraints",
+		values: func(v *templateValues) {
+			minLength, maxItems, minimum := 1, 3, 0.5
+			v.Action.Options = action.ParametersList{
+				{Name: "opt_enum", Type: jsonschema.String, Default: "fast", Enum: []any{"fast", "slow"}},
+				{Name: "opt_pattern", Type: jsonschema.String, Default: "v1"},
+				{Name: "opt_number", Type: jsonschema.Number, Default: 1.0},
+				{Name: "opt_array", Type: jsonschema.Array, Items: &action.DefArrayItems{Type: jsonschema.String}, Default: []any{"a"}},
+			}
+			v.setConstraints("opt_pattern", &paramConstraints{Pattern: "^v[0-9]+$", MinLength: &minLength})
+			v.setConstraints("opt_number", &paramConstraints{Minimum: &minimum})
+			v.setConstraints("opt_array", &paramConstraints{MaxItems: &maxItems})
+		},
+	},
+	{
+		name: "multi-line description",
+		values: func(v *templateValues) {
+			v.Action.Title = "Check: title with a colon"
+			v.Action.Description = "First line of the description.\nSecond line: with a colon # and a hash."
+			v.Action.Aliases = []string{"check_alias"}
+		},
+	},
+	{
+		name: "env",
+		values: func(v *templateValues) {
+			v.Runtime.Container.Env = action.EnvSlice{"FOO=bar", "EMPTY="}
+			v.Runtime.Shell.Env = action.EnvSlice{"FOO=bar", "EMPTY="}
+		},
+	},
+	{
+		name: "services",
+		values: func(v *templateValues) {
+			v.Services = launchrServices
+			v.Action.Options = action.ParametersList{
+				{Name: "settings", Type: jsonschema.String, Default: "value"},
+			}
+		},
+	},
+	{
+		name: "extra hosts",
+		values: func(v *templateValues) {
+			v.Runtime.Container.ExtraHosts = []string{"host.docker.internal:host-gateway", "example.local:127.0.0.1"}
+		},
+	},
+}
+
+// checkFailure is a template that failed to render or produced invalid output
+type checkFailure struct {
+	combination string
+	caseName    string
+	file        string
+	err         error
+}
+
+// checkTemplates renders every runtime and preset combination against [templateChecks]
+func (t *templateManager) checkTemplates() ([]*checkFailure, int, error) {
+	entries, err := t.catalog()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	gen := newGenerator("", t, generatorOptions{})
+	m := newMetadataCollector(nil, nil, &scaffoldConfig{}, t, promptOptions{}, "", false)
+	var failures []*checkFailure
+	total := 0
+	for _, info := range entries {
+		combination := string(info.Runtime)
+		if info.Preset != "" {
+			combination += "/" + info.Preset
+		}
+
+		for _, c := range templateChecks {
+			total++
+			values := newCheckValues(info)
+			c.values(values)
+			err = m.deriveValues(values)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			err = m.resolveCheckVars(values)
+			if err != nil {
+				failures = append(failures, &checkFailure{combination: combination, caseName: c.name, file: presetManifestFile, err: err})
+				continue
+			}
+
+			for _, f := range checkRender(gen, values) {
+				f.combination = combination
+				f.caseName = c.name
+				failures = append(failures, f)
+			}
+		}
+	}
+
+	// Project skeletons don't depend on runtimes and presets, they are checked once.
+	projects := map[string]func() ([]*renderedFile, error){
+		templatesAppDir:        t.renderCheckApp,
+		templatesPluginRepoDir: t.renderCheckPluginRepo,
+	}
+	for _, name := range slices.Sorted(maps.Keys(projects)) {
+		total++
+		for _, f := range checkProject(projects[name]) {
+			f.combination = name
+			f.caseName = "default"
+			failures = append(failures, f)
+		}
+	}
+
+	return failures, total, nil
+}
+
+// resolveCheckVars resolves preset variables the way a non-interactive run does,
+// required variables without a default get a synthetic value
+func (m *metadataCollector) resolveCheckVars(values *templateValues) error {
+	filesFS, err := m.templates.getFilesFS(values)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := m.templates.getPresetManifest(filesFS)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range manifest.Vars {
+		if v.Required && v.Default == nil {
+			values.Vars[v.Name] = checkVarValue(v)
+		}
+	}
+
+	return m.collectVars(values)
+}
+
+// checkVarValue returns a synthetic value of a variable, the first allowed value if it's restricted
+func checkVarValue(v *manifestVar) any {
+	if len(v.Enum) > 0 {
+		return v.Enum[0]
+	}
+
+	switch v.Type {
+	case jsonschema.Integer:
+		return 1
+	case jsonschema.Number:
+		return 1.5
+	case jsonschema.Boolean:
+		return true
+	default:
+		return "check"
+	}
+}
+
+// newCheckValues creates synthetic values for a runtime or preset
+func newCheckValues(info *templateInfo) *templateValues {
+	return &templateValues{
+		Definition: &action.Definition{
+			Action: &action.DefAction{
+				Title:     "Check",
+				Aliases:   []string{},
+				Arguments: action.ParametersList{},
+				Options:   action.ParametersList{},
+			},
+			Runtime: &action.DefRuntime{
+				Type:      info.Runtime,
+				Container: &action.DefRuntimeContainer{Image: "check:latest"},
+				Shell:     &action.DefRuntimeShell{},
+			},
+		},
+		ID:              "check",
+		ContainerPreset: info.Preset,
+		Vars:            make(map[string]any),
+	}
+}
+
+// checkRender renders values and verifies the output files
+func checkRender(gen *generator, values *templateValues) []*checkFailure {
+	files, err := gen.render(values)
+	if err != nil {
+		return []*checkFailure{{file: "render", err: err}}
+	}
+
+	return verifyRenderedFiles(values.ID, files)
+}
+
+// checkProject renders a project skeleton and verifies the output files
+func checkProject(render func() ([]*renderedFile, error)) []*checkFailure {
+	files, err := render()
+	if err != nil {
+		return []*checkFailure{{file: "render", err: err}}
+	}
+
+	return verifyRenderedFiles("", files)
+}
+
+// renderCheckApp renders the application skeleton against synthetic values
+func (t *templateManager) renderCheckApp() ([]*renderedFile, error) {
+	values, err := newAppValues("check", "example.com/check", defaultLaunchrVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.renderApp(values)
+}
+
+// renderCheckPluginRepo renders the standalone plugin project against synthetic values with every service
+func (t *templateManager) renderCheckPluginRepo() ([]*renderedFile, error) {
+	values, err := newPluginRepoValues("check", "example.com/check", defaultLaunchrVersion, 10, launchrServiceNames())
+	if err != nil {
+		return nil, err
+	}
+
+	return t.renderPluginRepo(values)
+}
+
+// verifyRenderedFiles checks every rendered file and collects failures
+func verifyRenderedFiles(id string, files []*renderedFile) []*checkFailure {
+	var failures []*checkFailure
+	for _, f := range files {
+		err := verifyRenderedFile(id, f)
+		if err != nil {
+			failures = append(failures, &checkFailure{file: f.path, err: err})
+		}
+	}
+
+	return failures
+}
+
+// verifyRenderedFile checks the rendered file is valid for its kind
+func verifyRenderedFile(id string, f *renderedFile) error {
+	switch {
+	case f.path == "action.yaml":
+		return verifyDefinition(id, f.content)
+	case filepath.Base(f.path) == "go.mod":
+		_, err := modfile.Parse(f.path, f.content, nil)
+		return err
+	case filepath.Ext(f.path) == ".go":
+		_, err := parser.ParseFile(token.NewFileSet(), f.path, f.content, parser.AllErrors)
+		return err
+	case filepath.Ext(f.path) == ".yaml" || filepath.Ext(f.path) == ".yml":
+		var v any
+		return yaml.Unmarshal(f.content, &v)
+	}
+
+	return nil
+}
+
+// printCheckFailures prints failures grouped by combination and case
+func printCheckFailures(failures []*checkFailure, total int) error {
+	if len(failures) == 0 {
+		launchr.Term().Success().Printfln("All %d template checks passed", total)
+		return nil
+	}
+
+	for _, f := range failures {
+		launchr.Term().Error().Printfln("%s [%s] %s: %s", f.combination, f.caseName, f.file, strings.TrimSpace(f.err.Error()))
+	}
+
+	return fmt.Errorf("%d of %d template checks failed", countFailedChecks(failures), total)
+}
+
+// countFailedChecks counts combination and case pairs with at least one failure
+func countFailedChecks(failures []*checkFailure) int {
+	seen := make(map[string]bool)
+	for _, f := range failures {
+		seen[f.combination+"\x00"+f.caseName] = true
+	}
+
+	return len(seen)
+}
@@ -0,0 +1,62 @@
+package scaffold
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheckTemplatesPresetVars(t *testing.T) {
+	const manifest = `vars:
+  - name: flavor
+    enum: [slim, full]
+    default: slim
+  - name: token
+    required: true
+  - name: port
+    type: integer
+    required: true
+`
+	tests := []struct {
+		name     string
+		template string   // Template of main.sh of the shell runtime
+		want     []string // Failed files of the shell runtime
+	}{
+		{name: "declared vars", template: "echo {{ len .Vars.flavor }} {{ len .Vars.token }} {{ .Vars.port }}\n"},
+		{name: "undeclared var", template: "echo {{ len .Vars.missing }}\n", want: []string{"render"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templates := newTemplateManager(newLayeredFS([]templateSource{
+				{origin: "test", fs: fstest.MapFS{
+					"files/shell/" + presetManifestFile: &fstest.MapFile{Data: []byte(manifest)},
+					"files/shell/main.sh.tmpl":          &fstest.MapFile{Data: []byte(tt.template)},
+				}},
+				{origin: "embedded", fs: embeddedTemplates()},
+			}), newPresetRegistry())
+
+			failures, _, err := templates.checkTemplates()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Every check case of the shell runtime fails the same way.
+			failed := make(map[string]bool)
+			for _, f := range failures {
+				if f.combination != string(runtimeShell) {
+					t.Errorf("unexpected failure of %s: %s: %v", f.combination, f.file, f.err)
+					continue
+				}
+				failed[f.file] = true
+			}
+			if len(failed) != len(tt.want) {
+				t.Errorf("failed files = %v, want %v", failed, tt.want)
+			}
+			for _, file := range tt.want {
+				if !failed[file] {
+					t.Errorf("failed files = %v, want %v", failed, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/launchrctl/launchr/pkg/action"
+)
+
+// collision is a name of the new action already taken by something else
+type collision struct {
+	kind  string // ID or alias
+	name  string
+	taken string // What the name is taken by
+}
+
+// collisionError reports every name of the new action that is already taken
+type collisionError struct {
+	collisions []*collision
+}
+
+func (e *collisionError) Error() string {
+	if len(e.collisions) == 1 {
+		c := e.collisions[0]
+		return fmt.Sprintf("%s '%s' %s", c.kind, c.name, c.taken)
+	}
+
+	var b strings.Builder
+	b.WriteString("names of the action are already taken:")
+	for _, c := range e.collisions {
+		fmt.Fprintf(&b, "\n  - %s '%s' %s", c.kind, c.name, c.taken)
+	}
+	return b.String()
+}
+
+// actionsDir returns the directory actions of the runtime are generated in
+func actionsDir(outputDir string, rt action.DefRuntimeType) string {
+	if rt == runtimePlugin {
+		return filepath.Join(outputDir, "plugins")
+	}
+
+	return filepath.Join(outputDir, "actions")
+}
+
+// parseAliases splits a comma-separated list of aliases
+func parseAliases(s string) []string {
+	var aliases []string
+	for _, alias := range strings.Split(s, ",") {
+		alias = strings.TrimSpace(alias)
+		if alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases
+}
+
+// builtinCommands returns names and aliases of commands of launchr itself, an action can't be called by them.
+// Discovered actions are commands of the root command too, they're checked separately.
+func (m *metadataCollector) builtinCommands() []string {
+	if m.rootCmd == nil {
+		return nil
+	}
+
+	var names []string
+	for _, cmd := range m.rootCmd.Commands() {
+		if _, isAction := m.actionManager.Get(cmd.Name()); isAction {
+			continue
+		}
+		names = append(names, cmd.Name())
+		names = append(names, cmd.Aliases...)
+	}
+
+	return names
+}
+
+// isRegenerated checks the discovered action is the one in the directory the new action is generated to
+func (m *metadataCollector) isRegenerated(a *action.Action, id string, rt action.DefRuntimeType) bool {
+	if !m.allowExisting {
+		return false
+	}
+
+	dir, err := filepath.Abs(filepath.Join(actionsDir(m.outputDir, rt), sanitizeForPath(id)))
+	if err != nil {
+		return false
+	}
+	actionDir, err := filepath.Abs(a.Dir())
+	if err != nil {
+		return false
+	}
+
+	return actionDir == dir
+}
+
+// checkCollisions checks the ID and aliases of the new action don't collide with other actions,
+// built-in commands, each other or an action directory not discovered yet in the output path
+func (m *metadataCollector) checkCollisions(id string, aliases []string, rt action.DefRuntimeType) error {
+	collisions := m.findCollisions(id, aliases, rt)
+
+	_, exists := m.actionManager.Get(id)
+	if id != "" && !exists && !m.allowExisting {
+		dir := filepath.Join(actionsDir(m.outputDir, rt), sanitizeForPath(id))
+		_, err := os.Stat(filepath.Join(dir, "action.yaml"))
+		switch {
+		case err == nil:
+			collisions = append(collisions, &collision{
+				kind:  "ID",
+				name:  id,
+				taken: fmt.Sprintf("is taken by the action in %s, it isn't discovered yet", dir),
+			})
+		case !errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("failed to check action directory %s: %w", dir, err)
+		}
+	}
+
+	if len(collisions) > 0 {
+		return &collisionError{collisions: collisions}
+	}
+
+	return nil
+}
+
+// findCollisions finds the ID and aliases of the new action taken by discovered actions,
+// built-in commands or the action itself, the ID is skipped if it's empty
+func (m *metadataCollector) findCollisions(id string, aliases []string, rt action.DefRuntimeType) []*collision {
+	var res []*collision
+	all := m.actionManager.All()
+	ids := make([]string, 0, len(all))
+	for otherID, a := range all {
+		// The regenerated action takes the names of the existing one.
+		if otherID != id || !m.isRegenerated(a, id, rt) {
+			ids = append(ids, otherID)
+		}
+	}
+	slices.Sort(ids)
+	builtin := m.builtinCommands()
+
+	check := func(kind, name string) {
+		add := func(format string, a ...any) {
+			res = append(res, &collision{kind: kind, name: name, taken: fmt.Sprintf(format, a...)})
+		}
+
+		if slices.Contains(builtin, name) {
+			add("is a built-in command")
+		}
+		for _, otherID := range ids {
+			if name == otherID {
+				add("is taken by an existing action")
+			}
+			if def := all[otherID].ActionDef(); def != nil && slices.Contains(def.Aliases, name) {
+				add("is an alias of action '%s'", otherID)
+			}
+		}
+	}
+
+	if id != "" {
+		check("ID", id)
+	}
+	for i, alias := range aliases {
+		switch {
+		case alias == id:
+			res = append(res, &collision{kind: "alias", name: alias, taken: "is the ID of the action"})
+		case slices.Contains(aliases[:i], alias):
+			res = append(res, &collision{kind: "alias", name: alias, taken: "is listed more than once"})
+		default:
+			check("alias", alias)
+		}
+	}
+
+	return res
+}
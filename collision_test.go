@@ -0,0 +1,100 @@
+package scaffold
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/launchrctl/launchr"
+	"github.com/launchrctl/launchr/pkg/action"
+)
+
+// newTestAction creates a discovered action with the definition in the directory
+func newTestAction(t *testing.T, id, dir, definition string) *action.Action {
+	t.Helper()
+	return action.New(action.StringID(id), &action.YamlLoader{Bytes: []byte(definition)}, action.NewDiscoveryFS(nil, ""), filepath.Join(dir, "action.yaml"))
+}
+
+func TestCheckCollisions(t *testing.T) {
+	outputDir := t.TempDir()
+	writeTestFiles(t, filepath.Join(outputDir, "actions", "ondisk"), map[string]testFile{"action.yaml": {content: "action: {}", mode: 0600}})
+
+	actions := map[string]*action.Action{
+		"build":  newTestAction(t, "build", filepath.Join(outputDir, "actions", "build"), "action:\n  title: Build\n  alias:\n    - b\n    - mk\n\nruntime: plugin\n"),
+		"deploy": newTestAction(t, "deploy", filepath.Join(t.TempDir(), "deploy"), "action:\n  title: Deploy\n  alias:\n    - d\n\nruntime: plugin\n"),
+	}
+
+	// Actions are commands of the root command along with commands of launchr.
+	root := &launchr.Command{Use: "launchr"}
+	root.AddCommand(
+		&launchr.Command{Use: "help"},
+		&launchr.Command{Use: "version", Aliases: []string{"v"}},
+		&launchr.Command{Use: "build", Aliases: []string{"b", "mk"}},
+		&launchr.Command{Use: "deploy", Aliases: []string{"d"}},
+	)
+
+	tests := []struct {
+		name          string
+		id            string
+		aliases       []string
+		runtime       action.DefRuntimeType
+		allowExisting bool
+		want          []string // Collisions as "<kind> <name>"
+	}{
+		{name: "free names", id: "fresh", aliases: []string{"f"}, runtime: runtimeShell},
+		{name: "ID of an action", id: "build", runtime: runtimeShell, want: []string{"ID build"}},
+		{name: "alias of an action", id: "fresh", aliases: []string{"mk", "deploy"}, runtime: runtimeShell, want: []string{"alias mk", "alias deploy"}},
+		{name: "built-in command", id: "help", aliases: []string{"v"}, runtime: runtimeShell, want: []string{"ID help", "alias v"}},
+		{name: "own names", id: "fresh", aliases: []string{"fresh", "x", "x"}, runtime: runtimeShell, want: []string{"alias fresh", "alias x"}},
+		{name: "undiscovered action", id: "ondisk", runtime: runtimeShell, want: []string{"ID ondisk"}},
+		{name: "undiscovered action of another runtime", id: "ondisk", runtime: runtimePlugin},
+		{name: "regenerated action", id: "build", aliases: []string{"b"}, runtime: runtimeShell, allowExisting: true},
+		{name: "regenerated undiscovered action", id: "ondisk", runtime: runtimeShell, allowExisting: true},
+		{name: "action in another directory", id: "deploy", aliases: []string{"d"}, runtime: runtimeShell, allowExisting: true, want: []string{"ID deploy", "alias d"}},
+		{name: "regenerated action of another runtime", id: "build", runtime: runtimePlugin, allowExisting: true, want: []string{"ID build"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMetadataCollector(&testManager{actions: actions}, root, &scaffoldConfig{}, nil, promptOptions{}, outputDir, tt.allowExisting)
+
+			err := m.checkCollisions(tt.id, tt.aliases, tt.runtime)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("checkCollisions() error = %v", err)
+				}
+				return
+			}
+
+			collisionErr, ok := err.(*collisionError)
+			if !ok {
+				t.Fatalf("checkCollisions() error = %v, want collisions %v", err, tt.want)
+			}
+			var got []string
+			for _, c := range collisionErr.collisions {
+				got = append(got, c.kind+" "+c.name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collisions = %v, want %v\n%s", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestBuiltinCommands(t *testing.T) {
+	root := &launchr.Command{Use: "launchr"}
+	root.AddCommand(
+		&launchr.Command{Use: "completion [bash|zsh]"},
+		&launchr.Command{Use: "login", Aliases: []string{"auth"}},
+		&launchr.Command{Use: "build"},
+	)
+	m := newMetadataCollector(&testManager{actions: map[string]*action.Action{
+		"build": action.NewFromYAML("build", []byte("action:\n  title: Build\n\nruntime: plugin\n")),
+	}}, root, &scaffoldConfig{}, nil, promptOptions{}, "", false)
+
+	got := strings.Join(m.builtinCommands(), ",")
+	if want := "completion,login,auth"; got != want {
+		t.Errorf("builtinCommands() = %s, want %s", got, want)
+	}
+}
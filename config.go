@@ -0,0 +1,34 @@
+package scaffold
+
+import (
+	"fmt"
+
+	"github.com/launchrctl/launchr"
+)
+
+// scaffoldConfig is the "scaffold" section of the project config, for example:
+//
+//	scaffold:
+//	  image:
+//	    registry: registry.local
+//	    namespace: team
+//	    tag: "{{version}}"
+//	    version: "0.1.0"
+type scaffoldConfig struct {
+	Image imageNamingConfig `yaml:"image"`
+}
+
+// loadScaffoldConfig reads the scaffold section from the project config
+func loadScaffoldConfig(cfg launchr.Config) (*scaffoldConfig, error) {
+	c := &scaffoldConfig{}
+	if cfg == nil {
+		return c, nil
+	}
+
+	err := cfg.Get("scaffold", c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scaffold config: %w", err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,280 @@
+package scaffold
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+)
+
+// paramConstraints holds JSON schema keywords of a parameter missing in [action.DefParameter],
+// launchr passes them to the input schema as is
+type paramConstraints struct {
+	Pattern   string   `yaml:"pattern,omitempty"`
+	MinLength *int     `yaml:"minLength,omitempty"`
+	MaxLength *int     `yaml:"maxLength,omitempty"`
+	Minimum   *float64 `yaml:"minimum,omitempty"`
+	Maximum   *float64 `yaml:"maximum,omitempty"`
+	MinItems  *int     `yaml:"minItems,omitempty"`
+	MaxItems  *int     `yaml:"maxItems,omitempty"`
+	Format    string   `yaml:"format,omitempty"`
+}
+
+// constraintFormats are string formats supported by the JSON schema validator
+var constraintFormats = []string{"date-time", "date", "time", "duration", "email", "hostname", "ipv4", "ipv6", "uri", "uri-reference", "uuid", "regex"}
+
+// constraintKeys are names of constraints accepted by [paramConstraints.set], enum is set on the parameter itself
+var constraintKeys = []string{"enum", "pattern", "minLength", "maxLength", "minimum", "maximum", "minItems", "maxItems", "format"}
+
+// isEmpty checks if no constraint is set
+func (c *paramConstraints) isEmpty() bool {
+	return c == nil || *c == paramConstraints{}
+}
+
+// set parses a constraint value entered as a string
+func (c *paramConstraints) set(key, value string) error {
+	value = strings.TrimSpace(value)
+	var err error
+	switch key {
+	case "pattern":
+		c.Pattern = value
+	case "format":
+		c.Format = value
+	case "minLength":
+		c.MinLength, err = parseConstraintInt(key, value)
+	case "maxLength":
+		c.MaxLength, err = parseConstraintInt(key, value)
+	case "minItems":
+		c.MinItems, err = parseConstraintInt(key, value)
+	case "maxItems":
+		c.MaxItems, err = parseConstraintInt(key, value)
+	case "minimum":
+		c.Minimum, err = parseConstraintFloat(key, value)
+	case "maximum":
+		c.Maximum, err = parseConstraintFloat(key, value)
+	default:
+		return fmt.Errorf("unknown constraint '%s', expected one of %v", key, constraintKeys)
+	}
+
+	return err
+}
+
+// get formats a constraint value as a string, the reverse of [paramConstraints.set]
+func (c *paramConstraints) get(key string) string {
+	if c == nil {
+		return ""
+	}
+
+	formatInt := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	}
+	formatFloat := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+
+	switch key {
+	case "pattern":
+		return c.Pattern
+	case "format":
+		return c.Format
+	case "minLength":
+		return formatInt(c.MinLength)
+	case "maxLength":
+		return formatInt(c.MaxLength)
+	case "minItems":
+		return formatInt(c.MinItems)
+	case "maxItems":
+		return formatInt(c.MaxItems)
+	case "minimum":
+		return formatFloat(c.Minimum)
+	case "maximum":
+		return formatFloat(c.Maximum)
+	default:
+		return ""
+	}
+}
+
+func parseConstraintInt(key, value string) (*int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative integer, got '%s'", key, value)
+	}
+
+	return &v, nil
+}
+
+func parseConstraintFloat(key, value string) (*float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number, got '%s'", key, value)
+	}
+
+	return &v, nil
+}
+
+// parseEnum splits enum values entered as a string, values are separated by "|"
+func parseEnum(value string) []any {
+	var enum []any
+	for _, v := range strings.Split(value, "|") {
+		if v = strings.TrimSpace(v); v != "" {
+			enum = append(enum, v)
+		}
+	}
+
+	return enum
+}
+
+// normalizeConstraints converts enum values to the parameter type and checks the constraints
+// are applicable to the type, consistent and satisfied by the default value
+func normalizeConstraints(param *action.DefParameter, c *paramConstraints) error {
+	err := checkConstraints(param, c)
+	if err != nil {
+		return fmt.Errorf("invalid constraints of parameter '%s': %w", param.Name, err)
+	}
+
+	if param.Default == nil {
+		return nil
+	}
+
+	err = checkConstrainedValue(param, c, param.Default)
+	if err != nil {
+		return fmt.Errorf("default value of parameter '%s' %w", param.Name, err)
+	}
+
+	return nil
+}
+
+// fillDefault sets the zero value of the type as the default of a parameter declared without one.
+// A constrained parameter is left without a default, the zero value may break its constraints.
+func fillDefault(param *action.DefParameter, c *paramConstraints) error {
+	if param.Default != nil || len(param.Enum) > 0 || !c.isEmpty() {
+		return nil
+	}
+
+	var err error
+	param.Default, err = jsonschema.EnsureType(param.Type, nil)
+	if err != nil {
+		return err
+	}
+	// Numbers are written with a fraction, see [normalizeParameter].
+	if v, ok := param.Default.(int); ok && param.Type == jsonschema.Number {
+		param.Default = float64(v)
+	}
+
+	return nil
+}
+
+// checkConstraints checks constraints are applicable to the parameter type and consistent
+func checkConstraints(param *action.DefParameter, c *paramConstraints) error {
+	if c == nil {
+		c = &paramConstraints{}
+	}
+
+	isString := param.Type == jsonschema.String
+	isNumeric := param.Type == jsonschema.Number || param.Type == jsonschema.Integer
+	isArray := param.Type == jsonschema.Array
+	for _, rule := range []struct {
+		key     string
+		set     bool
+		allowed bool
+	}{
+		{"enum", len(param.Enum) > 0, !isArray && param.Type != jsonschema.Boolean},
+		{"pattern", c.Pattern != "", isString},
+		{"format", c.Format != "", isString},
+		{"minLength", c.MinLength != nil, isString},
+		{"maxLength", c.MaxLength != nil, isString},
+		{"minimum", c.Minimum != nil, isNumeric},
+		{"maximum", c.Maximum != nil, isNumeric},
+		{"minItems", c.MinItems != nil, isArray},
+		{"maxItems", c.MaxItems != nil, isArray},
+	} {
+		if rule.set && !rule.allowed {
+			return fmt.Errorf("%s is not applicable to type %s", rule.key, param.Type)
+		}
+	}
+
+	for i, e := range param.Enum {
+		str, ok := e.(string)
+		if !ok || isString {
+			continue
+		}
+		v, err := jsonschema.ConvertStringToType(str, param.Type)
+		if err != nil {
+			return fmt.Errorf("enum value '%s' is not of type %s", str, param.Type)
+		}
+		param.Enum[i] = v
+	}
+
+	if c.Pattern != "" {
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if c.Format != "" && !slices.Contains(constraintFormats, c.Format) {
+		return fmt.Errorf("unknown format '%s', expected one of %v", c.Format, constraintFormats)
+	}
+	if c.MinLength != nil && c.MaxLength != nil && *c.MinLength > *c.MaxLength {
+		return fmt.Errorf("minLength %d is greater than maxLength %d", *c.MinLength, *c.MaxLength)
+	}
+	if c.Minimum != nil && c.Maximum != nil && *c.Minimum > *c.Maximum {
+		return fmt.Errorf("minimum %v is greater than maximum %v", *c.Minimum, *c.Maximum)
+	}
+	if c.MinItems != nil && c.MaxItems != nil && *c.MinItems > *c.MaxItems {
+		return fmt.Errorf("minItems %d is greater than maxItems %d", *c.MinItems, *c.MaxItems)
+	}
+
+	return nil
+}
+
+// checkConstrainedValue checks a value of the parameter satisfies its constraints
+func checkConstrainedValue(param *action.DefParameter, c *paramConstraints, v any) error {
+	if c == nil {
+		c = &paramConstraints{}
+	}
+
+	if len(param.Enum) > 0 && !slices.ContainsFunc(param.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(v) }) {
+		return fmt.Errorf("'%v' is not one of %v", v, param.Enum)
+	}
+
+	switch vv := v.(type) {
+	case string:
+		l := utf8.RuneCountInString(vv)
+		if c.MinLength != nil && l < *c.MinLength {
+			return fmt.Errorf("'%s' is shorter than %d characters", vv, *c.MinLength)
+		}
+		if c.MaxLength != nil && l > *c.MaxLength {
+			return fmt.Errorf("'%s' is longer than %d characters", vv, *c.MaxLength)
+		}
+		if c.Pattern != "" && !regexp.MustCompile(c.Pattern).MatchString(vv) {
+			return fmt.Errorf("'%s' doesn't match pattern '%s'", vv, c.Pattern)
+		}
+	case int, float64:
+		f, _ := strconv.ParseFloat(fmt.Sprint(vv), 64)
+		if c.Minimum != nil && f < *c.Minimum {
+			return fmt.Errorf("%v is less than minimum %v", vv, *c.Minimum)
+		}
+		if c.Maximum != nil && f > *c.Maximum {
+			return fmt.Errorf("%v is greater than maximum %v", vv, *c.Maximum)
+		}
+	case []any:
+		if c.MinItems != nil && len(vv) < *c.MinItems {
+			return fmt.Errorf("has fewer than %d items", *c.MinItems)
+		}
+		if c.MaxItems != nil && len(vv) > *c.MaxItems {
+			return fmt.Errorf("has more than %d items", *c.MaxItems)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+package scaffold
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"gopkg.in/yaml.v3"
+)
+
+// definitionOverride returns the origin of definition templates overriding the generated action.yaml,
+// empty if the definition is emitted from values
+func (t *templateManager) definitionOverride(runtimeType action.DefRuntimeType) string {
+	for _, name := range []string{"action.yaml.tmpl", fmt.Sprintf("%s.yaml.tmpl", runtimeType)} {
+		origin := t.origin(path.Join(templatesDefinitionDir, name))
+		if origin != "" && origin != "embedded" {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// emitDefinition serializes the definition to action.yaml in the order launchr documents its keys
+func emitDefinition(values *templateValues) (*renderedFile, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+
+	if values.WD != "" && values.Runtime.Type == runtimeContainer {
+		err := mapAppend(root, "working_directory", values.WD)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	act, err := emitAction(values.Action, values.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	root.Content = append(root.Content, yamlScalar("action"), act)
+
+	rt, err := emitRuntime(values)
+	if err != nil {
+		return nil, err
+	}
+	root.Content = append(root.Content, yamlScalar("runtime"), rt)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate action.yaml: %w", err)
+	}
+	_ = enc.Close()
+
+	// Separate the runtime section like hand-written definitions do.
+	content := strings.Replace(buf.String(), "\nruntime:", "\n\nruntime:", 1)
+	return &renderedFile{path: "action.yaml", content: []byte(content)}, nil
+}
+
+// emitAction serializes the action section
+func emitAction(a *action.DefAction, constraints map[string]*paramConstraints) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	err := mapAppend(node, "title", a.Title)
+	if err != nil {
+		return nil, err
+	}
+	if a.Description != "" {
+		node.Content = append(node.Content, yamlScalar("description"), yamlTextNode(a.Description))
+	}
+	if len(a.Aliases) > 0 {
+		err = mapAppend(node, "alias", a.Aliases)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, params := range []struct {
+		key  string
+		list action.ParametersList
+	}{{"arguments", a.Arguments}, {"options", a.Options}} {
+		if len(params.list) == 0 {
+			continue
+		}
+
+		seq := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, p := range params.list {
+			pn, err := emitParameter(p, constraints[p.Name])
+			if err != nil {
+				return nil, err
+			}
+			seq.Content = append(seq.Content, pn)
+		}
+		node.Content = append(node.Content, yamlScalar(params.key), seq)
+	}
+
+	return node, nil
+}
+
+// parameterKeys is the order of the parameter keys in action.yaml, other fields follow them
+var parameterKeys = []string{"name", "shorthand", "title", "description", "type", "required", "default", "enum"}
+
+// emitParameter serializes an argument or an option along with its constraints
+func emitParameter(p *action.DefParameter, c *paramConstraints) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	err := emitFields(node, p, parameterKeys...)
+	if err != nil {
+		return nil, fmt.Errorf("parameter %s: %w", p.Name, err)
+	}
+
+	// Values are short, they read better on a single line.
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key == "default" || key == "enum" {
+			setFlowStyle(node.Content[i+1])
+		}
+	}
+
+	if !c.isEmpty() {
+		var cn yaml.Node
+		err = cn.Encode(c)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		node.Content = append(node.Content, cn.Content...)
+	}
+
+	return node, nil
+}
+
+// emitRuntime serializes the runtime section
+func emitRuntime(values *templateValues) (*yaml.Node, error) {
+	switch values.Runtime.Type {
+	case runtimePlugin:
+		return yamlScalar(string(runtimePlugin)), nil
+	case runtimeShell:
+		return emitShellRuntime(values.Runtime.Shell)
+	default:
+		return emitContainerRuntime(values)
+	}
+}
+
+// emitContainerRuntime serializes the container runtime with commented-out parameters hints for the command
+func emitContainerRuntime(values *templateValues) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	err := mapAppend(node, "type", runtimeContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitFields(node, values.Runtime.Container, "image", "extra_hosts", "env", "build", "command")
+	if err != nil {
+		return nil, err
+	}
+
+	// The command is kept even if it's empty, parameters are passed to it by uncommenting the hints.
+	var key, command *yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "command" {
+			key, command = node.Content[i], node.Content[i+1]
+		}
+	}
+	if key == nil {
+		key, command = yamlScalar("command"), &yaml.Node{Kind: yaml.SequenceNode}
+		node.Content = append(node.Content, key, command)
+	}
+
+	var hints []string
+	for _, params := range []action.ParametersList{values.Action.Arguments, values.Action.Options} {
+		for _, p := range params {
+			hints = append(hints, fmt.Sprintf("- \"{{ .%s }}\"", p.Name))
+		}
+	}
+	if len(hints) > 0 {
+		comment := "#" + strings.Join(hints, "\n#")
+		if command.Kind == yaml.SequenceNode && len(command.Content) > 0 {
+			command.Content[len(command.Content)-1].FootComment = comment
+		} else {
+			key.FootComment = comment
+		}
+	}
+
+	return node, nil
+}
+
+// emitShellRuntime serializes the shell runtime
+func emitShellRuntime(s *action.DefRuntimeShell) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	err := mapAppend(node, "type", runtimeShell)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitFields(node, s, "env", "script")
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// emitFields adds non-empty fields of a struct to a mapping node, fields are named by their yaml tags.
+// Keys in the order come first, other fields follow in the declaration order.
+func emitFields(node *yaml.Node, v any, order ...string) error {
+	type field struct {
+		key   string
+		value reflect.Value
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	var fields []field
+	for i := range rv.NumField() {
+		sf := rv.Type().Field(i)
+		key, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if !sf.IsExported() || key == "" || key == "-" || isEmptyValue(rv.Field(i)) {
+			continue
+		}
+		fields = append(fields, field{key: key, value: rv.Field(i)})
+	}
+
+	rank := func(key string) int {
+		if i := slices.Index(order, key); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+	slices.SortStableFunc(fields, func(a, b field) int {
+		return cmp.Compare(rank(a.key), rank(b.key))
+	})
+
+	for _, f := range fields {
+		n, err := emitValue(f.value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", f.key, err)
+		}
+		node.Content = append(node.Content, yamlScalar(f.key), n)
+	}
+
+	return nil
+}
+
+// emitValue encodes a field value, empty fields of nested structs are skipped as well
+// and strings spanning multiple lines are written as literal blocks
+func emitValue(v reflect.Value) (*yaml.Node, error) {
+	if _, ok := v.Interface().(yaml.Marshaler); !ok {
+		switch iv := reflect.Indirect(v); iv.Kind() {
+		case reflect.Struct:
+			node := &yaml.Node{Kind: yaml.MappingNode}
+			return node, emitFields(node, iv.Interface())
+		case reflect.String:
+			return yamlTextNode(iv.String()), nil
+		}
+	}
+
+	var node yaml.Node
+	err := node.Encode(withFloatNodes(v.Interface()))
+	return &node, err
+}
+
+// isEmptyValue checks if a field value is not worth writing, e.g. false, an empty string or list
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// mapAppend encodes a value and adds it to a mapping node
+func mapAppend(node *yaml.Node, key string, value any) error {
+	var v yaml.Node
+	err := v.Encode(withFloatNodes(value))
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	node.Content = append(node.Content, yamlScalar(key), &v)
+	return nil
+}
+
+// yamlScalar creates a string scalar node, the encoder quotes it when needed
+func yamlScalar(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
+
+// yamlTextNode creates a string node rendered as a literal block when it spans multiple lines
+func yamlTextNode(v string) *yaml.Node {
+	n := yamlScalar(v)
+	if strings.Contains(strings.TrimRight(v, "\n"), "\n") {
+		n.Style = yaml.LiteralStyle
+	}
+
+	return n
+}
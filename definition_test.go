@@ -0,0 +1,212 @@
+package scaffold
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEmitDefinition(t *testing.T) {
+	minLength := 2
+	tests := []struct {
+		name    string
+		runtime action.DefRuntimeType
+		values  func(v *templateValues)
+		want    map[string]any // Expected values by a dot-separated path
+		absent  []string       // Paths that must not be emitted
+		comment string         // Expected text of a comment
+	}{
+		{
+			name:    "plugin",
+			runtime: runtimePlugin,
+			want:    map[string]any{"action.title": "Test", "runtime": "plugin"},
+			absent:  []string{"action.description", "action.alias", "action.arguments", "working_directory"},
+		},
+		{
+			name:    "quoted values",
+			runtime: runtimePlugin,
+			values: func(v *templateValues) {
+				v.Action.Title = "Deploy: all # services"
+				v.Action.Description = "First line\nSecond: line"
+				v.Action.Aliases = []string{"yes", "0123"}
+			},
+			want: map[string]any{
+				"action.title":       "Deploy: all # services",
+				"action.description": "First line\nSecond: line",
+				"action.alias":       []any{"yes", "0123"},
+			},
+		},
+		{
+			name:    "parameters",
+			runtime: runtimePlugin,
+			values: func(v *templateValues) {
+				v.Action.Arguments = action.ParametersList{
+					{Name: "target", Title: "Target", Description: "Where to deploy", Type: jsonschema.String, Required: true},
+				}
+				v.Action.Options = action.ParametersList{
+					{Name: "count", Shorthand: "c", Type: jsonschema.Number, Default: 3.0, Enum: []any{1.5, 3.0}},
+					{Name: "tags", Type: jsonschema.Array, Items: &action.DefArrayItems{Type: jsonschema.String}, Default: []any{}},
+					{Name: "mode", Type: jsonschema.String, Default: "fast"},
+				}
+				v.setConstraints("mode", &paramConstraints{Pattern: "^[a-z]+$", MinLength: &minLength})
+			},
+			want: map[string]any{
+				"action.arguments.0.name":        "target",
+				"action.arguments.0.title":       "Target",
+				"action.arguments.0.description": "Where to deploy",
+				"action.arguments.0.required":    true,
+				"action.options.0.shorthand":     "c",
+				"action.options.0.default":       3.0,
+				"action.options.0.enum":          []any{1.5, 3.0},
+				"action.options.1.items.type":    "string",
+				"action.options.1.default":       []any{},
+				"action.options.2.pattern":       "^[a-z]+$",
+				"action.options.2.minLength":     2,
+			},
+			absent: []string{"action.options.0.required", "action.options.2.items", "action.options.2.title"},
+		},
+		{
+			name:    "container",
+			runtime: runtimeContainer,
+			values: func(v *templateValues) {
+				v.WD = "{{ .current_working_dir }}"
+				v.Runtime.Container.Image = "registry.local/test:1.0"
+				v.Runtime.Container.Command = action.StrSliceOrStr{"sh", "/action/main.sh"}
+				v.Runtime.Container.Env = action.EnvSlice{"FOO=bar"}
+				v.Runtime.Container.ExtraHosts = []string{"db:127.0.0.1"}
+				v.Action.Options = action.ParametersList{{Name: "verbose", Type: jsonschema.Boolean, Default: false}}
+				if err := yaml.Unmarshal([]byte("context: ./docker\nargs:\n  USER_NAME: app\n"), &v.Runtime.Container.Build); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: map[string]any{
+				"working_directory":            "{{ .current_working_dir }}",
+				"runtime.type":                 "container",
+				"runtime.image":                "registry.local/test:1.0",
+				"runtime.command":              []any{"sh", "/action/main.sh"},
+				"runtime.env":                  []any{"FOO=bar"},
+				"runtime.extra_hosts":          []any{"db:127.0.0.1"},
+				"runtime.build.context":        "./docker",
+				"runtime.build.args.USER_NAME": "app",
+			},
+			comment: `#- "{{ .verbose }}"`,
+		},
+		{
+			name:    "container without command",
+			runtime: runtimeContainer,
+			values: func(v *templateValues) {
+				v.Runtime.Container.Image = "test:latest"
+				v.Action.Arguments = action.ParametersList{{Name: "target", Type: jsonschema.String}}
+			},
+			want:    map[string]any{"runtime.command": []any{}},
+			absent:  []string{"runtime.build", "runtime.env"},
+			comment: `#- "{{ .target }}"`,
+		},
+		{
+			name:    "shell",
+			runtime: runtimeShell,
+			values: func(v *templateValues) {
+				v.WD = "{{ .current_working_dir }}"
+				v.Runtime.Shell.Env = action.EnvSlice{"FOO=bar"}
+				v.Runtime.Shell.Script = "echo one\necho two\n"
+			},
+			want: map[string]any{
+				"runtime.type":   "shell",
+				"runtime.env":    []any{"FOO=bar"},
+				"runtime.script": "echo one\necho two\n",
+			},
+			absent: []string{"working_directory"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := (&scaffoldAction{runtime: tt.runtime, id: "test", title: "Test"}).getDefaultValues()
+			if tt.values != nil {
+				tt.values(values)
+			}
+
+			f, err := emitDefinition(values)
+			if err != nil {
+				t.Fatalf("emitDefinition() error = %v", err)
+			}
+
+			var doc any
+			err = yaml.Unmarshal(f.content, &doc)
+			if err != nil {
+				t.Fatalf("invalid yaml:\n%s\nerror: %v", f.content, err)
+			}
+
+			for path, want := range tt.want {
+				got, ok := yamlPath(doc, path)
+				if !ok {
+					t.Errorf("%s is missing in:\n%s", path, f.content)
+					continue
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %#v, want %#v", path, got, want)
+				}
+			}
+			for _, path := range tt.absent {
+				if _, ok := yamlPath(doc, path); ok {
+					t.Errorf("%s is emitted in:\n%s", path, f.content)
+				}
+			}
+			if tt.comment != "" && !strings.Contains(string(f.content), tt.comment) {
+				t.Errorf("comment %s is missing in:\n%s", tt.comment, f.content)
+			}
+
+			err = verifyDefinition(values.ID, f.content)
+			if err != nil {
+				t.Errorf("emitted definition is invalid: %v\n%s", err, f.content)
+			}
+		})
+	}
+}
+
+func TestEmitParameterKeysOrder(t *testing.T) {
+	p := &action.DefParameter{Name: "count", Title: "Count", Type: jsonschema.Integer, Required: true, Default: 1}
+	node, err := emitParameter(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for i := 0; i < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+	want := []string{"name", "title", "type", "required", "default"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+}
+
+// yamlPath returns a value of a decoded document by a dot-separated path of keys and list indexes
+func yamlPath(doc any, path string) (any, bool) {
+	v := doc
+	for _, part := range strings.Split(path, ".") {
+		switch vv := v.(type) {
+		case map[string]any:
+			var ok bool
+			v, ok = vv[part]
+			if !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i >= len(vv) {
+				return nil, false
+			}
+			v = vv[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return v, true
+}
@@ -1,10 +1,15 @@
 package scaffold
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"text/template"
 
 	"github.com/launchrctl/launchr"
@@ -17,65 +22,80 @@ const (
 	runtimeShell     action.DefRuntimeType = "shell"
 )
 
+// conflictMode defines how the generator treats an existing non-empty action directory
+type conflictMode int
+
+const (
+	conflictFail  conflictMode = iota // Refuse to write into a non-empty directory
+	conflictForce                     // Overwrite the files produced by templates
+	conflictMerge                     // Write only the files missing on disk
+)
+
+// newConflictMode returns the conflict mode chosen by the --force and --merge options
+func newConflictMode(force, merge bool) (conflictMode, error) {
+	switch {
+	case force && merge:
+		return conflictFail, fmt.Errorf("options --force and --merge can't be used together")
+	case force:
+		return conflictForce, nil
+	case merge:
+		return conflictMerge, nil
+	}
+
+	return conflictFail, nil
+}
+
+// generatorOptions holds the generator behaviour switches
+type generatorOptions struct {
+	dryRun      bool
+	conflict    conflictMode
+	saveAnswers bool // Store collected values in the action directory for a later replay
+}
+
 // generator handles generating action files from templates
 type generator struct {
 	dirManager  *directoryManager
 	tmplManager *templateManager
+	opts        generatorOptions
 }
 
 // newGenerator creates a new generator instance
-func newGenerator(prefix string) *generator {
+func newGenerator(prefix string, tmplManager *templateManager, opts generatorOptions) *generator {
 	return &generator{
 		dirManager:  newDirectoryManager(prefix),
-		tmplManager: &templateManager{},
+		tmplManager: tmplManager,
+		opts:        opts,
 	}
 }
 
 func (g *generator) generate(values *templateValues) error {
-	// Create an output directory if it doesn't exist
-	actionDir, err := g.dirManager.ensureActionDir(values.ID)
+	// Render everything in memory first, so template errors don't leave partial output.
+	files, err := g.render(values)
 	if err != nil {
 		return err
 	}
 
-	launchr.Term().Info().Printfln("Generating action in %s", actionDir)
-
-	success := false
-	// Defer cleanup that will run at the end of the function
-	defer func() {
-		if !success && actionDir != "" {
-			// Safety check: Verify actionDir is within our expected directory structure
-			expectedPrefix := g.dirManager.prefix
-			if !strings.HasPrefix(actionDir, expectedPrefix) {
-				launchr.Term().Warning().Printfln("Not removing directory outside of expected path: %s", actionDir)
-				return
-			}
-
-			// Additional check: Verify the directory exists before attempting removal
-			if info, err := os.Stat(actionDir); err != nil || !info.IsDir() {
-				return // Directory doesn't exist or isn't a directory, no need to remove
-			}
-
-			// Proceed with removal after all safety checks
-			if err = os.RemoveAll(actionDir); err != nil {
-				launchr.Term().Warning().Printfln("Failed to clean up directory %s: %v", actionDir, err)
-			}
+	actionDir := g.dirManager.getActionDir(values.ID)
+	if g.opts.dryRun {
+		// Nothing is written in the dry run, the rendered definition is verified instead.
+		err = verifyRenderedDefinition(values.ID, files)
+		if err != nil {
+			return fmt.Errorf("generated action is invalid: %w", err)
 		}
-	}()
+	}
 
-	err = g.generateDefinition(actionDir, values)
-	if err != nil {
+	w, err := g.writeOutput(actionDir, files)
+	if err != nil || w == nil {
 		return err
 	}
 
-	err = g.generateFiles(actionDir, values)
+	// Templates may produce a definition launchr can't load, don't leave a broken action behind.
+	err = verifyDefinitionFile(values.ID, actionDir)
 	if err != nil {
-		return err
+		w.rollback()
+		return fmt.Errorf("generated action is invalid, changes were rolled back: %w", err)
 	}
 
-	// Mark the operation as successful
-	success = true
-
 	launchr.Term().Success().Printfln(
 		"Action %s successfully generated in %s",
 		values.ID,
@@ -84,50 +104,114 @@ func (g *generator) generate(values *templateValues) error {
 	return nil
 }
 
-func (g *generator) generateDefinition(outputDir string, values *templateValues) error {
-	yamlTemplate, err := g.tmplManager.getDefinitionTemplate(values.Runtime.Type)
+// writeOutput writes rendered files to the directory, a non-empty directory is handled according to
+// the conflict mode. Files are only previewed in the dry run, the returned writer is nil then.
+func (g *generator) writeOutput(dir string, files []*renderedFile) (*fileWriter, error) {
+	nonEmpty, err := g.dirManager.isNonEmpty(dir)
 	if err != nil {
-		return fmt.Errorf("failed to generate action.yaml: %w", err)
+		return nil, err
 	}
 
-	templates := []*template.Template{yamlTemplate}
-	return g.tmplManager.renderTemplates(outputDir, values, templates)
+	if g.opts.dryRun {
+		return nil, g.preview(dir, files, nonEmpty)
+	}
+
+	if nonEmpty {
+		switch g.opts.conflict {
+		case conflictFail:
+			return nil, fmt.Errorf("directory %s already exists and is not empty, use --force to overwrite generated files or --merge to write only missing ones", dir)
+		case conflictMerge:
+			files = g.skipExisting(dir, files)
+		}
+	}
+
+	launchr.Term().Info().Printfln("Generating files in %s", dir)
+
+	// An interrupt stops writing, changes made so far are rolled back instead of being left half-applied.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := newFileWriter()
+	err = w.writeFiles(ctx, dir, files)
+	if err != nil {
+		// Only undo what this run changed in the destination, pre-existing content stays untouched.
+		w.rollback()
+		return nil, err
+	}
+
+	return w, nil
 }
 
-func (g *generator) generateFiles(output string, values *templateValues) error {
-	filesDir := string(values.Runtime.Type)
-	if values.Runtime.Type == runtimeContainer {
-		filesDir = fmt.Sprintf("%s/%s", values.Runtime.Type, values.ContainerPreset)
+// render executes the definition and runtime templates without touching the filesystem
+func (g *generator) render(values *templateValues) ([]*renderedFile, error) {
+	files, err := g.renderDefinition(values)
+	if err != nil {
+		return nil, err
 	}
 
-	dirs, err := g.tmplManager.getTemplateSubdirectories(filesDir)
+	runtimeFiles, err := g.renderFiles(values)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	files = append(files, runtimeFiles...)
 
-	dirs = append(dirs, filesDir)
-	for _, d := range dirs {
-		outputDir := filepath.Join(output, strings.TrimPrefix(d, filesDir))
-		err = ensureDir(outputDir)
+	if g.opts.saveAnswers {
+		answers, err := renderAnswers(values)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		files = append(files, answers)
+	}
 
-		templates, err := g.tmplManager.getRuntimeTemplates(d)
-		if err != nil {
-			if strings.Contains(err.Error(), "template: pattern matches no files") {
-				continue
-			}
+	return files, nil
+}
 
-			return err
-		}
-		err = g.tmplManager.renderTemplates(outputDir, values, templates)
+func (g *generator) renderDefinition(values *templateValues) ([]*renderedFile, error) {
+	if values.editedDefinition != nil {
+		return []*renderedFile{{path: "action.yaml", content: values.editedDefinition}}, nil
+	}
+
+	// Definition templates are used only when overridden to customize comments or layout.
+	if g.tmplManager.definitionOverride(values.Runtime.Type) == "" {
+		f, err := emitDefinition(values)
 		if err != nil {
-			return err
+			return nil, err
 		}
+
+		return []*renderedFile{f}, nil
 	}
 
-	return nil
+	yamlTemplate, err := g.tmplManager.getDefinitionTemplate(values.Runtime.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate action.yaml: %w", err)
+	}
+
+	templates := []*template.Template{yamlTemplate}
+	return g.tmplManager.renderTemplates("", values, templates)
+}
+
+func (g *generator) renderFiles(values *templateValues) ([]*renderedFile, error) {
+	filesFS, err := g.tmplManager.getFilesFS(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.tmplManager.renderTree(filesFS, values)
+}
+
+// skipExisting filters out files that are already present in the action directory
+func (g *generator) skipExisting(actionDir string, files []*renderedFile) []*renderedFile {
+	missing := make([]*renderedFile, 0, len(files))
+	for _, f := range files {
+		outputPath := filepath.Join(actionDir, f.path)
+		if _, err := os.Stat(outputPath); err == nil {
+			launchr.Term().Info().Printfln("Skipping existing file %s", outputPath)
+			continue
+		}
+		missing = append(missing, f)
+	}
+
+	return missing
 }
 
 // directoryManager handles the action directory creation and validation
@@ -148,21 +232,17 @@ func (dm *directoryManager) getActionDir(actionID string) string {
 	return filepath.Join(dm.prefix, safeID)
 }
 
-// ensureActionDir ensures the action directory exists and is empty/unique
-// Returns the created directory path or an error
-func (dm *directoryManager) ensureActionDir(actionID string) (string, error) {
-	// Get the full path for the action
-	actionDir := dm.getActionDir(actionID)
-	err := ensureDir(actionDir)
-	return actionDir, err
-}
-
-func ensureDir(dirPath string) error {
-	if err := os.MkdirAll(dirPath, 0750); err != nil {
-		return fmt.Errorf("failed to create action directory: %w", err)
+// isNonEmpty checks if the action directory exists and already contains files
+func (dm *directoryManager) isNonEmpty(actionDir string) (bool, error) {
+	entries, err := os.ReadDir(actionDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read action directory %s: %w", actionDir, err)
 	}
 
-	return nil
+	return len(entries) > 0, nil
 }
 
 // sanitizeForPath sanitizes a string for use as a directory name
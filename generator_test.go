@@ -0,0 +1,36 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateDryRunVerifiesDefinition(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		wantErr    bool
+	}{
+		{name: "valid", definition: "action:\n  title: Test\n\nruntime: plugin\n"},
+		{name: "invalid yaml", definition: "action:\n  title: [Test\n\nruntime: plugin\n", wantErr: true},
+		{name: "invalid default", definition: "action:\n  title: Test\n  options:\n    - name: count\n      type: integer\n      default: many\n\nruntime: plugin\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix := filepath.Join(t.TempDir(), "plugins")
+			gen := newGenerator(prefix, newTemplateManager(embeddedTemplates(), newPresetRegistry()), generatorOptions{dryRun: true})
+			values := (&scaffoldAction{runtime: runtimePlugin, id: "test", title: "Test"}).getDefaultValues()
+			values.editedDefinition = []byte(tt.definition)
+
+			err := gen.generate(values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(prefix); !os.IsNotExist(err) {
+				t.Errorf("dry run created %s", prefix)
+			}
+		})
+	}
+}
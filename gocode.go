@@ -0,0 +1,131 @@
+package scaffold
+
+import (
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+)
+
+// goTemplateNames are identifiers the plugin template declares or imports around input variables
+var goTemplateNames = []string{"a", "p", "input", "toSlice", "err", "settings", "launchr", "action", "context"}
+
+// goInput describes a variable the plugin template extracts from the action input
+type goInput struct {
+	Var      string // Go variable name
+	Method   string // Arg or Opt
+	Name     string // Parameter name
+	Type     string // Go type, items type for arrays
+	Slice    bool   // Array parameter converted to a typed slice
+	Optional bool   // Argument may be missing, the value is checked with comma ok
+}
+
+// goPackage derives a Go package name from the action ID, e.g. "my-action" becomes "myaction"
+func goPackage(id string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, id)
+	name = strings.TrimLeftFunc(name, unicode.IsDigit)
+
+	switch {
+	case name == "":
+		return "plugin"
+	case name == "main" || token.IsKeyword(name):
+		// A plugin can't be the main package.
+		return name + "plugin"
+	}
+
+	return name
+}
+
+// goType returns the Go type of values launchr passes for a JSON schema type
+func goType(t jsonschema.Type) string {
+	switch t {
+	case jsonschema.Integer:
+		return "int"
+	case jsonschema.Number:
+		return "float64"
+	case jsonschema.Boolean:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// goInputs lists input variables for arguments and options of the action
+func goInputs(a *action.DefAction) []*goInput {
+	var res []*goInput
+	used := slices.Clone(goTemplateNames)
+	add := func(method string, p *action.DefParameter) {
+		in := &goInput{
+			Var:      goVarName(p.Name, used),
+			Method:   method,
+			Name:     p.Name,
+			Type:     goType(p.Type),
+			Optional: method == "Arg" && !p.Required && p.Default == nil,
+		}
+		if p.Type == jsonschema.Array {
+			in.Slice = true
+			in.Type = "string"
+			if p.Items != nil {
+				in.Type = goType(p.Items.Type)
+			}
+		}
+		used = append(used, in.Var)
+		res = append(res, in)
+	}
+
+	for _, p := range a.Arguments {
+		add("Arg", p)
+	}
+	for _, p := range a.Options {
+		add("Opt", p)
+	}
+
+	return res
+}
+
+// hasSliceInputs checks if any input is converted to a typed slice
+func hasSliceInputs(inputs []*goInput) bool {
+	return slices.ContainsFunc(inputs, func(in *goInput) bool { return in.Slice })
+}
+
+// goVarName converts a parameter name to a camel case Go variable name not clashing
+// with keywords, predeclared identifiers and already used names, e.g. "opt_int" becomes "optInt"
+func goVarName(name string, used []string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_':
+			upper = b.Len() > 0
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	v := b.String()
+	if v == "" {
+		v = "value"
+	}
+	// Names like "type" or "string" would break the generated code.
+	if token.IsKeyword(v) || types.Universe.Lookup(v) != nil || slices.Contains(used, v) {
+		v += "Value"
+	}
+	for base, i := v, 2; slices.Contains(used, v); i++ {
+		v = base + strconv.Itoa(i)
+	}
+
+	return v
+}
@@ -0,0 +1,131 @@
+package scaffold
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+)
+
+func TestGoVarName(t *testing.T) {
+	tests := []struct {
+		name string
+		used []string
+		want string
+	}{
+		{name: "opt_int", want: "optInt"},
+		{name: "opt__multi_word", want: "optMultiWord"},
+		{name: "_private", want: "private"},
+		{name: "trailing_", want: "trailing"},
+		{name: "_", want: "value"},
+		{name: "type", want: "typeValue"},
+		{name: "string", want: "stringValue"},
+		{name: "launchr", used: goTemplateNames, want: "launchrValue"},
+		{name: "action", used: goTemplateNames, want: "actionValue"},
+		{name: "context", used: goTemplateNames, want: "contextValue"},
+		{name: "input", used: goTemplateNames, want: "inputValue"},
+		{name: "opt_int", used: []string{"optInt"}, want: "optIntValue"},
+		{name: "opt_int", used: []string{"optInt", "optIntValue"}, want: "optIntValue2"},
+		{name: "type", used: []string{"typeValue", "typeValue2"}, want: "typeValue3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := goVarName(tt.name, tt.used); got != tt.want {
+				t.Errorf("goVarName(%q, %v) = %s, want %s", tt.name, tt.used, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoPackage(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "my-action", want: "myaction"},
+		{id: "My_Action.v2", want: "myactionv2"},
+		{id: "2fa", want: "fa"},
+		{id: "123", want: "plugin"},
+		{id: "-", want: "plugin"},
+		{id: "main", want: "mainplugin"},
+		{id: "type", want: "typeplugin"},
+		{id: "go-to", want: "gotoplugin"},
+		{id: "café", want: "caf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := goPackage(tt.id); got != tt.want {
+				t.Errorf("goPackage(%q) = %s, want %s", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoInputs(t *testing.T) {
+	tests := []struct {
+		name string
+		def  *action.DefAction
+		want []goInput
+	}{
+		{name: "no parameters", def: &action.DefAction{}},
+		{
+			name: "arguments and options",
+			def: &action.DefAction{
+				Arguments: action.ParametersList{
+					{Name: "target", Type: jsonschema.String, Required: true},
+					{Name: "count", Type: jsonschema.Integer},
+					{Name: "ratio", Type: jsonschema.Number, Default: 0.5},
+				},
+				Options: action.ParametersList{
+					{Name: "dry_run", Type: jsonschema.Boolean, Default: false},
+					{Name: "tags", Type: jsonschema.Array, Items: &action.DefArrayItems{Type: jsonschema.Integer}},
+					{Name: "files", Type: jsonschema.Array},
+				},
+			},
+			want: []goInput{
+				{Var: "target", Method: "Arg", Name: "target", Type: "string"},
+				{Var: "count", Method: "Arg", Name: "count", Type: "int", Optional: true},
+				{Var: "ratio", Method: "Arg", Name: "ratio", Type: "float64"},
+				{Var: "dryRun", Method: "Opt", Name: "dry_run", Type: "bool"},
+				{Var: "tags", Method: "Opt", Name: "tags", Type: "int", Slice: true},
+				{Var: "files", Method: "Opt", Name: "files", Type: "string", Slice: true},
+			},
+		},
+		{
+			name: "reserved and duplicate names",
+			def: &action.DefAction{
+				Arguments: action.ParametersList{
+					{Name: "context", Type: jsonschema.String, Required: true},
+					{Name: "err", Type: jsonschema.String, Required: true},
+				},
+				Options: action.ParametersList{
+					{Name: "opt_int", Type: jsonschema.Integer},
+					{Name: "optInt", Type: jsonschema.Integer},
+					{Name: "opt__int", Type: jsonschema.Integer},
+				},
+			},
+			want: []goInput{
+				{Var: "contextValue", Method: "Arg", Name: "context", Type: "string"},
+				{Var: "errValue", Method: "Arg", Name: "err", Type: "string"},
+				{Var: "optInt", Method: "Opt", Name: "opt_int", Type: "int"},
+				{Var: "optIntValue", Method: "Opt", Name: "optInt", Type: "int"},
+				{Var: "optIntValue2", Method: "Opt", Name: "opt__int", Type: "int"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []goInput
+			for _, in := range goInputs(tt.def) {
+				got = append(got, *in)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("goInputs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
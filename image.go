@@ -0,0 +1,60 @@
+package scaffold
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// templateExprRegex matches a template expression like {{ .current_uid }}
+var templateExprRegex = regexp.MustCompile(`{{[^{}]*}}`)
+
+// imageNamingConfig defines how a container image name is derived from the action ID.
+// Pattern and Tag support {{id}} and {{version}} placeholders.
+type imageNamingConfig struct {
+	Registry  string `yaml:"registry"`
+	Namespace string `yaml:"namespace"`
+	Tag       string `yaml:"tag"`     // Defaults to "latest"
+	Version   string `yaml:"version"` // Value of {{version}}, defaults to "latest"
+	Pattern   string `yaml:"pattern"` // Full image reference, takes precedence over registry, namespace and tag
+}
+
+// imageFor derives an image reference for the action ID
+func (c imageNamingConfig) imageFor(id string) string {
+	pattern := c.Pattern
+	if pattern == "" {
+		pattern = "{{id}}:" + cmp.Or(c.Tag, "latest")
+		if ns := strings.Trim(c.Namespace, "/"); ns != "" {
+			pattern = ns + "/" + pattern
+		}
+		if registry := strings.TrimSuffix(c.Registry, "/"); registry != "" {
+			pattern = registry + "/" + pattern
+		}
+	}
+
+	// Image repository names must be lowercase.
+	r := strings.NewReplacer(
+		"{{id}}", strings.ToLower(id),
+		"{{version}}", cmp.Or(c.Version, "latest"),
+	)
+	return r.Replace(pattern)
+}
+
+// validateImageRef checks the image is a valid reference like "registry.local/team/name:tag"
+func validateImageRef(image string) error {
+	if image == "" {
+		return errors.New("image can't be empty")
+	}
+
+	// Launchr template expressions are resolved when the action runs, the rest of the reference is validated.
+	_, err := reference.ParseNormalizedNamed(templateExprRegex.ReplaceAllString(image, "x"))
+	if err != nil {
+		return fmt.Errorf("invalid image reference '%s': %w", image, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package scaffold
+
+import "testing"
+
+func TestValidateImageRef(t *testing.T) {
+	tests := []struct {
+		image   string
+		wantErr bool
+	}{
+		{image: "alpine"},
+		{image: "myaction:latest"},
+		{image: "registry.local:5000/team/myaction:0.1.0"},
+		{image: "registry.local/team/myaction:{{ .version }}"},
+		{image: "{{ .registry }}/myaction:latest"},
+		{image: "", wantErr: true},
+		{image: "MyAction:latest", wantErr: true},
+		{image: "myaction:bad tag", wantErr: true},
+		{image: "foo{{ bar :x", wantErr: true},
+		{image: "foo }} bar:x", wantErr: true},
+		{image: "myaction:{{ .version }}:extra", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			err := validateImageRef(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateImageRef(%q) error = %v, wantErr %v", tt.image, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnswersKeepExplicitImageOnly(t *testing.T) {
+	m := newMetadataCollector(nil, nil, &scaffoldConfig{}, newTemplateManager(embeddedTemplates(), newPresetRegistry()), promptOptions{}, t.TempDir(), false)
+	tests := []struct {
+		name      string
+		image     string
+		wantImage string // Image recorded in the answers
+	}{
+		{name: "derived", image: "", wantImage: ""},
+		{name: "explicit", image: "registry.local/custom:1.0", wantImage: "registry.local/custom:1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := (&scaffoldAction{runtime: runtimeContainer, id: "test", title: "Test", containerPreset: "sh"}).getDefaultValues()
+			values.Runtime.Container.Image = tt.image
+			if err := m.deriveValues(values); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := newActionSpec(values).Image; got != tt.wantImage {
+				t.Errorf("recorded image = %q, want %q", got, tt.wantImage)
+			}
+
+			// The derived image follows the ID.
+			values.ID = "renamed"
+			if err := m.deriveValues(values); err != nil {
+				t.Fatal(err)
+			}
+			want := tt.image
+			if want == "" {
+				want = "renamed:latest"
+			}
+			if got := values.Runtime.Container.Image; got != want {
+				t.Errorf("image after renaming = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// templateSource is a single layer of templates
+type templateSource struct {
+	origin string // Human-readable origin of templates, e.g. a directory path
+	fs     fs.FS
+}
+
+// layeredFS resolves files through template sources, the first source containing a file wins.
+// Directory listings are merged, so a source only needs to ship the files it overrides.
+type layeredFS struct {
+	sources []templateSource
+}
+
+// newLayeredFS creates a filesystem from sources ordered by priority
+func newLayeredFS(sources []templateSource) *layeredFS {
+	return &layeredFS{
+		sources: sources,
+	}
+}
+
+// Open implements [fs.FS] interface.
+func (l *layeredFS) Open(name string) (fs.File, error) {
+	for _, src := range l.sources {
+		f, err := src.fs.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir implements [fs.ReadDirFS] interface.
+func (l *layeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	seen := make(map[string]bool)
+	found := false
+	for _, src := range l.sources {
+		list, err := fs.ReadDir(src.fs, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		found = true
+		for _, entry := range list {
+			if !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// origin returns the origin of the source providing the file, empty if no source has it
+func (l *layeredFS) origin(name string) string {
+	for _, src := range l.sources {
+		if _, err := fs.Stat(src.fs, name); err == nil {
+			return src.origin
+		}
+	}
+
+	return ""
+}
+
+// templateSources returns template sources ordered by priority:
+// a directory passed with --templates, the project templates, the user templates and the embedded defaults.
+func templateSources(flagDir, projectDir, userDir string) ([]templateSource, error) {
+	var sources []templateSource
+	if flagDir != "" {
+		info, err := os.Stat(flagDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read templates directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("templates path %s is not a directory", flagDir)
+		}
+		sources = append(sources, newDirTemplateSource(flagDir))
+	}
+
+	// Optional directories are used only when they exist.
+	for _, dir := range []string{projectDir, userDir} {
+		if dir == "" {
+			continue
+		}
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			sources = append(sources, newDirTemplateSource(dir))
+		}
+	}
+
+	sources = append(sources, templateSource{origin: "embedded", fs: embeddedTemplates()})
+	return sources, nil
+}
+
+func newDirTemplateSource(dir string) templateSource {
+	return templateSource{
+		origin: dir,
+		fs:     os.DirFS(dir),
+	}
+}
+
+// projectTemplatesDir returns the project-level templates directory
+func projectTemplatesDir(wd string) string {
+	return filepath.Join(wd, ".launchr", "scaffold", "templates")
+}
+
+// userTemplatesDir returns the user-level templates directory, empty if the user config dir is unknown
+func userTemplatesDir(appName string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, appName, "scaffold", "templates")
+}
@@ -0,0 +1,133 @@
+package scaffold
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestLayers creates templates directories given with --templates, in the project and in the user config
+func newTestLayers(t *testing.T) (flagDir, wd, userDir string) {
+	t.Helper()
+	root := t.TempDir()
+	flagDir = filepath.Join(root, "flag")
+	wd = filepath.Join(root, "project")
+	userDir = filepath.Join(root, "user")
+
+	writeTestFiles(t, flagDir, map[string]testFile{
+		"files/shell/main.sh.tmpl": {content: "flag", mode: 0600},
+	})
+	writeTestFiles(t, projectTemplatesDir(wd), map[string]testFile{
+		"files/shell/main.sh.tmpl":  {content: "project", mode: 0600},
+		"files/shell/setup.sh.tmpl": {content: "project", mode: 0600},
+	})
+	writeTestFiles(t, userDir, map[string]testFile{
+		"files/shell/setup.sh.tmpl": {content: "user", mode: 0600},
+		"files/shell/lib.sh.tmpl":   {content: "user", mode: 0600},
+	})
+
+	return flagDir, wd, userDir
+}
+
+func TestTemplateSources(t *testing.T) {
+	flagDir, wd, userDir := newTestLayers(t)
+	projectDir := projectTemplatesDir(wd)
+
+	tests := []struct {
+		name    string
+		flagDir string
+		userDir string
+		want    []string // Origins by priority
+		wantErr bool
+	}{
+		{name: "every layer", flagDir: flagDir, userDir: userDir, want: []string{flagDir, projectDir, userDir, "embedded"}},
+		{name: "without flag", userDir: userDir, want: []string{projectDir, userDir, "embedded"}},
+		{name: "missing user directory", flagDir: flagDir, userDir: filepath.Join(userDir, "missing"), want: []string{flagDir, projectDir, "embedded"}},
+		{name: "missing flag directory", flagDir: filepath.Join(flagDir, "missing"), wantErr: true},
+		{name: "flag is a file", flagDir: filepath.Join(flagDir, "files", "shell", "main.sh.tmpl"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := templateSources(tt.flagDir, projectDir, tt.userDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("templateSources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, src := range sources {
+				got = append(got, src.origin)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("origins = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayeredFS(t *testing.T) {
+	flagDir, wd, userDir := newTestLayers(t)
+	sources, err := templateSources(flagDir, projectTemplatesDir(wd), userDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := newLayeredFS(sources)
+
+	embedded, err := fs.ReadFile(embeddedTemplates(), "files/container/sh/main.sh.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each file is taken from the first layer containing it.
+	files := []struct {
+		name   string
+		want   string
+		origin string
+	}{
+		{name: "files/shell/main.sh.tmpl", want: "flag", origin: flagDir},
+		{name: "files/shell/setup.sh.tmpl", want: "project", origin: projectTemplatesDir(wd)},
+		{name: "files/shell/lib.sh.tmpl", want: "user", origin: userDir},
+		{name: "files/container/sh/main.sh.tmpl", want: string(embedded), origin: "embedded"},
+	}
+	for _, f := range files {
+		t.Run(f.name, func(t *testing.T) {
+			got, err := fs.ReadFile(l, f.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != f.want {
+				t.Errorf("content = %q, want %q", got, f.want)
+			}
+			if origin := l.origin(f.name); origin != f.origin {
+				t.Errorf("origin = %s, want %s", origin, f.origin)
+			}
+		})
+	}
+
+	if _, err = l.Open("files/shell/missing.tmpl"); !os.IsNotExist(err) {
+		t.Errorf("Open() of a missing file error = %v, want not exist", err)
+	}
+
+	// Listings are merged across layers without duplicates.
+	entries, err := fs.ReadDir(l, "files/shell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"lib.sh.tmpl", "main.sh.tmpl", "setup.sh.tmpl"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ReadDir() = %v, want %v", names, want)
+	}
+
+	matches, err := fs.Glob(l, "files/shell/*.sh.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"files/shell/lib.sh.tmpl", "files/shell/main.sh.tmpl", "files/shell/setup.sh.tmpl"}; !reflect.DeepEqual(matches, want) {
+		t.Errorf("Glob() = %v, want %v", matches, want)
+	}
+}
@@ -0,0 +1,291 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/charmbracelet/huh"
+	"github.com/launchrctl/launchr"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// presetManifestFile is an optional file in a preset directory declaring extra template variables
+const presetManifestFile = "scaffold.yaml"
+
+// presetManifest describes a preset, it is read from [presetManifestFile]
+type presetManifest struct {
+	Description string         `yaml:"description"`
+	Version     string         `yaml:"version"`
+	Vars        []*manifestVar `yaml:"vars"`
+}
+
+// manifestVar is a custom variable exposed to templates as .Vars.<name>
+type manifestVar struct {
+	Name        string          `yaml:"name"`
+	Prompt      string          `yaml:"prompt"`
+	Description string          `yaml:"description"`
+	Type        jsonschema.Type `yaml:"type"`
+	Default     any             `yaml:"default"`
+	Enum        []any           `yaml:"enum"`
+	Required    bool            `yaml:"required"`
+	// Validate is a regular expression the value must match.
+	Validate string `yaml:"validate"`
+	// Condition is a template evaluated against collected values, the variable is used only if it renders "true".
+	Condition string `yaml:"condition"`
+
+	rgx  *regexp.Regexp
+	cond *template.Template
+}
+
+var manifestVarTypes = []jsonschema.Type{jsonschema.String, jsonschema.Number, jsonschema.Integer, jsonschema.Boolean}
+
+// getPresetManifest reads the manifest of files templates, an empty manifest is returned if there is none
+func (t *templateManager) getPresetManifest(filesFS fs.FS) (*presetManifest, error) {
+	manifestPath := presetManifestFile
+	data, err := fs.ReadFile(filesFS, manifestPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &presetManifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", manifestPath, err)
+	}
+
+	manifest := &presetManifest{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(manifest)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestPath, err)
+	}
+
+	err = manifest.init()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", manifestPath, err)
+	}
+
+	return manifest, nil
+}
+
+// init validates declared variables and prepares their validation rules
+func (pm *presetManifest) init() error {
+	seen := make(map[string]bool)
+	for _, v := range pm.Vars {
+		err := isValidName("variable", v.Name)
+		if err != nil {
+			return err
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("variable '%s' is declared twice", v.Name)
+		}
+		seen[v.Name] = true
+
+		if v.Type == "" {
+			v.Type = jsonschema.String
+		}
+		if !slices.Contains(manifestVarTypes, v.Type) {
+			return fmt.Errorf("variable '%s' has unsupported type '%s'", v.Name, v.Type)
+		}
+
+		if v.Validate != "" {
+			v.rgx, err = regexp.Compile(v.Validate)
+			if err != nil {
+				return fmt.Errorf("variable '%s' has invalid validation regex: %w", v.Name, err)
+			}
+		}
+
+		if v.Condition != "" {
+			v.cond, err = template.New(v.Name).Parse(v.Condition)
+			if err != nil {
+				return fmt.Errorf("variable '%s' has invalid condition: %w", v.Name, err)
+			}
+		}
+
+		if v.Default != nil {
+			v.Default, err = v.convert(v.Default)
+			if err != nil {
+				return fmt.Errorf("variable '%s' has invalid default: %w", v.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// get returns a declared variable by name
+func (pm *presetManifest) get(name string) *manifestVar {
+	for _, v := range pm.Vars {
+		if v.Name == name {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// isEnabled evaluates the variable condition against collected values
+func (v *manifestVar) isEnabled(values *templateValues) (bool, error) {
+	if v.cond == nil {
+		return true, nil
+	}
+
+	var buf bytes.Buffer
+	err := v.cond.Execute(&buf, values)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate condition of variable '%s': %w", v.Name, err)
+	}
+
+	return strings.TrimSpace(buf.String()) == "true", nil
+}
+
+// convert casts a value to the variable type and checks it against enum and validation regex
+func (v *manifestVar) convert(val any) (any, error) {
+	var err error
+	if str, ok := val.(string); ok && v.Type != jsonschema.String {
+		val, err = jsonschema.ConvertStringToType(str, v.Type)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	str := fmt.Sprint(val)
+	if len(v.Enum) > 0 && !slices.ContainsFunc(v.Enum, func(e any) bool { return fmt.Sprint(e) == str }) {
+		return nil, fmt.Errorf("value '%s' is not one of %v", str, v.Enum)
+	}
+	if v.rgx != nil && !v.rgx.MatchString(str) {
+		return nil, fmt.Errorf("value '%s' doesn't match %s", str, v.Validate)
+	}
+
+	return val, nil
+}
+
+// collectVars resolves preset variables from provided values, interactive prompts or defaults
+func (m *metadataCollector) collectVars(values *templateValues) error {
+	filesFS, err := m.templates.getFilesFS(values)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := m.templates.getPresetManifest(filesFS)
+	if err != nil {
+		return err
+	}
+
+	provided := values.Vars
+	values.Vars = make(map[string]any)
+	for name := range provided {
+		if manifest.get(name) == nil {
+			launchr.Term().Warning().Printfln("Variable '%s' is not declared by the preset manifest, skipping", name)
+		}
+	}
+
+	// Variables are resolved in order, so conditions may refer to previous ones.
+	for _, v := range manifest.Vars {
+		enabled, err := v.isEnabled(values)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			continue
+		}
+
+		val, ok := provided[v.Name]
+		switch {
+		case ok:
+			val, err = v.convert(val)
+			if err != nil {
+				return fmt.Errorf("invalid variable '%s': %w", v.Name, err)
+			}
+		case m.prompt.interactive:
+			val, err = m.promptVar(v)
+			if err != nil {
+				return err
+			}
+		case v.Default != nil:
+			val = v.Default
+		case v.Required:
+			return fmt.Errorf("variable '%s' is required by the preset manifest", v.Name)
+		default:
+			val, err = jsonschema.EnsureType(v.Type, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		values.Vars[v.Name] = val
+	}
+
+	return nil
+}
+
+// promptVar asks for a variable value
+func (m *metadataCollector) promptVar(v *manifestVar) (any, error) {
+	title := v.Prompt
+	if title == "" {
+		title = v.Name
+	}
+
+	var field huh.Field
+	var str string
+	var confirmed bool
+	switch {
+	case v.Type == jsonschema.Boolean:
+		confirmed, _ = v.Default.(bool)
+		field = huh.NewConfirm().
+			Title(title).
+			Description(v.Description).
+			Value(&confirmed)
+	case len(v.Enum) > 0:
+		options := make([]huh.Option[string], 0, len(v.Enum))
+		for _, e := range v.Enum {
+			options = append(options, huh.NewOption(fmt.Sprint(e), fmt.Sprint(e)))
+		}
+		if v.Default != nil {
+			str = fmt.Sprint(v.Default)
+		}
+		field = huh.NewSelect[string]().
+			Title(title).
+			Description(v.Description).
+			Options(options...).
+			Value(&str)
+	default:
+		if v.Default != nil {
+			str = fmt.Sprint(v.Default)
+		}
+		field = huh.NewInput().
+			Title(title).
+			Description(v.Description).
+			Value(&str).
+			Validate(func(s string) error {
+				if s == "" {
+					if v.Required {
+						return fmt.Errorf("%s can't be empty", v.Name)
+					}
+					return nil
+				}
+				_, err := v.convert(s)
+				return err
+			})
+	}
+
+	err := m.runForm(huh.NewGroup(field))
+	if err != nil {
+		return nil, fmt.Errorf("form error: %w", err)
+	}
+
+	if v.Type == jsonschema.Boolean {
+		return confirmed, nil
+	}
+	if str == "" {
+		return jsonschema.EnsureType(v.Type, nil)
+	}
+
+	return v.convert(str)
+}
@@ -0,0 +1,133 @@
+package scaffold
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGetPresetManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string         // Content of scaffold.yaml, the file is missing if empty
+		want     map[string]any // Declared variables with their defaults
+		wantErr  bool
+	}{
+		{name: "no manifest", want: map[string]any{}},
+		{
+			name: "defaults are converted",
+			manifest: `vars:
+  - name: port
+    type: integer
+    default: "8080"
+  - name: debug
+    type: boolean
+    default: "true"
+  - name: flavor
+    enum: [slim, full]
+    default: slim
+  - name: token
+    required: true
+`,
+			want: map[string]any{"port": 8080, "debug": true, "flavor": "slim", "token": nil},
+		},
+		{name: "unknown type", manifest: "vars:\n  - name: list\n    type: array\n", wantErr: true},
+		{name: "duplicate name", manifest: "vars:\n  - name: port\n  - name: port\n", wantErr: true},
+		{name: "invalid name", manifest: "vars:\n  - name: 1port\n", wantErr: true},
+		{name: "missing name", manifest: "vars:\n  - type: string\n", wantErr: true},
+		{name: "unknown field", manifest: "vars:\n  - name: port\n    kind: string\n", wantErr: true},
+		{name: "invalid regex", manifest: "vars:\n  - name: port\n    validate: \"[\"\n", wantErr: true},
+		{name: "invalid condition", manifest: "vars:\n  - name: port\n    condition: \"{{ .ID \"\n", wantErr: true},
+		{name: "default out of enum", manifest: "vars:\n  - name: flavor\n    enum: [slim, full]\n    default: fat\n", wantErr: true},
+		{name: "default not matching regex", manifest: "vars:\n  - name: port\n    validate: ^[0-9]+$\n    default: http\n", wantErr: true},
+		{name: "default of another type", manifest: "vars:\n  - name: port\n    type: integer\n    default: http\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filesFS := fstest.MapFS{}
+			if tt.manifest != "" {
+				filesFS[presetManifestFile] = &fstest.MapFile{Data: []byte(tt.manifest)}
+			}
+
+			manifest, err := newTemplateManager(fstest.MapFS{}, newPresetRegistry()).getPresetManifest(filesFS)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getPresetManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := make(map[string]any)
+			for _, v := range manifest.Vars {
+				got[v.Name] = v.Default
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("variables = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectVars(t *testing.T) {
+	const manifest = `vars:
+  - name: port
+    type: integer
+    default: 8080
+  - name: token
+    required: true
+  - name: cache
+    type: boolean
+  - name: cacheDir
+    condition: "{{ .Vars.cache }}"
+    default: /tmp/cache
+`
+	templates := newTemplateManager(fstest.MapFS{
+		"files/shell/main.sh.tmpl":          &fstest.MapFile{},
+		"files/shell/" + presetManifestFile: &fstest.MapFile{Data: []byte(manifest)},
+	}, newPresetRegistry())
+
+	tests := []struct {
+		name     string
+		provided map[string]any
+		want     map[string]any
+		wantErr  bool
+	}{
+		{
+			name:     "defaults",
+			provided: map[string]any{"token": "secret"},
+			want:     map[string]any{"port": 8080, "token": "secret", "cache": false},
+		},
+		{
+			name:     "provided values are converted",
+			provided: map[string]any{"port": "9090", "token": "secret", "cache": "true"},
+			want:     map[string]any{"port": 9090, "token": "secret", "cache": true, "cacheDir": "/tmp/cache"},
+		},
+		{
+			name:     "undeclared variable is skipped",
+			provided: map[string]any{"token": "secret", "other": "x"},
+			want:     map[string]any{"port": 8080, "token": "secret", "cache": false},
+		},
+		{name: "required variable without a value", provided: map[string]any{}, wantErr: true},
+		{name: "invalid value", provided: map[string]any{"port": "http", "token": "secret"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMetadataCollector(nil, nil, &scaffoldConfig{}, templates, promptOptions{}, "", false)
+			values := (&scaffoldAction{runtime: runtimeShell, id: "test", title: "Test"}).getDefaultValues()
+			values.Vars = tt.provided
+
+			err := m.collectVars(values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("collectVars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(values.Vars, tt.want) {
+				t.Errorf("vars = %v, want %v", values.Vars, tt.want)
+			}
+		})
+	}
+}
@@ -10,25 +10,91 @@ import (
 	"github.com/launchrctl/launchr"
 	"github.com/launchrctl/launchr/pkg/action"
 	"github.com/launchrctl/launchr/pkg/jsonschema"
+	"gopkg.in/yaml.v3"
 )
 
+// containerBuild builds the image of a container action from the generated Dockerfile for the current user
+const containerBuild = `
+context: ./
+args:
+  USER_ID: "{{ .current_uid }}"
+  GROUP_ID: "{{ .current_gid }}"
+  USER_NAME: launchr
+`
+
+// shellScript is the script of a shell action, it runs the generated main.sh
+const shellScript = `date
+pwd
+whoami
+env
+{{ .action_dir }}/main.sh
+`
+
 // metadataCollector handles the action data collection.
 type metadataCollector struct {
 	actionManager action.Manager
-	interactive   bool
+	rootCmd       *launchr.Command // Commands of launchr itself take names of actions, may be nil
+	config        *scaffoldConfig
+	templates     *templateManager
+	prompt        promptOptions
+	outputDir     string // Actions are generated in, see [actionsDir]
+	allowExisting bool   // Allow the ID of the discovered action in the target directory, used to regenerate it
+
+	// hidden holds conditions of hidden form groups, huh doesn't check them in the accessible mode.
+	hidden map[*huh.Group]func() bool
 }
 
 type templateValues struct {
 	*action.Definition
 	ID              string
 	ContainerPreset string
+	Vars            map[string]any // Custom variables declared in the preset manifest
+	// Constraints holds JSON schema constraints of arguments and options by parameter name.
+	Constraints map[string]*paramConstraints
+	// Services are injected into the plugin of the plugin runtime, see [launchrServices].
+	Services []*launchrService
+
+	// derived holds values derived by [metadataCollector.complete] rather than given by the user.
+	derived derivedValues
+
+	// editedDefinition is action.yaml edited by the user on the summary screen, it's written instead of the generated one.
+	editedDefinition []byte
+}
+
+// derivedValues marks values derived from other values, they're derived again when those change
+type derivedValues struct {
+	image   bool // Container image derived from the project naming and the ID
+	command bool // Container command taken from the preset
+}
+
+// setConstraints stores constraints of a parameter, empty constraints are dropped
+func (v *templateValues) setConstraints(name string, c *paramConstraints) {
+	if c.isEmpty() {
+		delete(v.Constraints, name)
+		return
+	}
+	if v.Constraints == nil {
+		v.Constraints = make(map[string]*paramConstraints)
+	}
+	v.Constraints[name] = c
+}
+
+// hasParameter checks if an argument or an option with the name is already collected
+func (v *templateValues) hasParameter(name string) bool {
+	isNamed := func(p *action.DefParameter) bool { return p.Name == name }
+	return slices.ContainsFunc(v.Action.Arguments, isNamed) || slices.ContainsFunc(v.Action.Options, isNamed)
 }
 
 // newMetadataCollector creates a new form generator
-func newMetadataCollector(manager action.Manager, interactive bool) *metadataCollector {
+func newMetadataCollector(manager action.Manager, rootCmd *launchr.Command, config *scaffoldConfig, templates *templateManager, prompt promptOptions, outputDir string, allowExisting bool) *metadataCollector {
 	return &metadataCollector{
 		actionManager: manager,
-		interactive:   interactive,
+		rootCmd:       rootCmd,
+		config:        config,
+		templates:     templates,
+		prompt:        prompt,
+		outputDir:     outputDir,
+		allowExisting: allowExisting,
 	}
 }
 
@@ -43,9 +109,21 @@ func (m *metadataCollector) validate(values *templateValues) error {
 		return err
 	}
 
-	_, ok := m.actionManager.Get(values.ID)
-	if ok {
-		return fmt.Errorf("action with ID '%s' already exists", values.ID)
+	err = m.checkCollisions(values.ID, values.Action.Aliases, values.Runtime.Type)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.templates.presets.resolve(values)
+	if err != nil {
+		return err
+	}
+
+	if values.Runtime.Type == runtimeContainer {
+		err = validateImageRef(values.Runtime.Container.Image)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -53,7 +131,7 @@ func (m *metadataCollector) validate(values *templateValues) error {
 
 // collectActionInfo interactively collects action information
 func (m *metadataCollector) collectActionInfo(values *templateValues) (*templateValues, error) {
-	if m.interactive {
+	if m.prompt.interactive {
 		launchr.Term().Info().Printfln("Running in interactive mode. Please fill the following fields. Press enter to skip a question.")
 		err := m.attachInteractiveForm(values)
 		if err != nil {
@@ -61,21 +139,85 @@ func (m *metadataCollector) collectActionInfo(values *templateValues) (*template
 		}
 	}
 
-	if values.Runtime.Type == runtimeContainer {
-		values.Runtime.Container.Image = fmt.Sprintf("%s:latest", values.ID)
+	return values, m.complete(values)
+}
+
+// complete derives values the user didn't provide and validates the result
+func (m *metadataCollector) complete(values *templateValues) error {
+	err := m.deriveValues(values)
+	if err != nil {
+		return err
 	}
 
-	err := m.validate(values)
+	err = m.collectVars(values)
 	if err != nil {
-		return values, err
+		return err
 	}
 
-	return values, nil
+	return m.validate(values)
+}
+
+// deriveValues derives runtime values the user didn't provide from the ID, preset and project config
+func (m *metadataCollector) deriveValues(values *templateValues) error {
+	// Keep an explicitly provided image, derive one from the project naming otherwise.
+	// The ID may have changed since the image was derived, so it's derived again.
+	if values.derived.image {
+		values.Runtime.Container.Image = ""
+		values.derived.image = false
+	}
+	if values.Runtime.Type == runtimeContainer && values.Runtime.Container.Image == "" {
+		values.Runtime.Container.Image = m.config.Image.imageFor(values.ID)
+		values.derived.image = true
+	}
+
+	// Use the preset default command unless one is already set.
+	// The preset may have changed since the command was taken, so it's taken again.
+	if values.derived.command {
+		values.Runtime.Container.Command = nil
+		values.derived.command = false
+	}
+	if values.Runtime.Type == runtimeContainer && len(values.Runtime.Container.Command) == 0 {
+		if p := m.templates.presets.get(values.ContainerPreset); p != nil && len(p.Command) > 0 {
+			values.Runtime.Container.Command = p.Command
+			values.derived.command = true
+		}
+	}
+
+	// The image is built from the generated Dockerfile unless the build is configured otherwise.
+	if values.Runtime.Type == runtimeContainer && values.Runtime.Container.Build == nil {
+		err := yaml.Unmarshal([]byte(containerBuild), &values.Runtime.Container.Build)
+		if err != nil {
+			return fmt.Errorf("failed to prepare container build: %w", err)
+		}
+	}
+
+	if values.Runtime.Type == runtimeShell && values.Runtime.Shell.Script == "" {
+		values.Runtime.Shell.Script = shellScript
+	}
+
+	return nil
 }
 
 func (m *metadataCollector) attachInteractiveForm(values *templateValues) error {
-	var aliasesStr string
-	form := huh.NewForm(
+	err := m.collectGeneralInfo(values)
+	if err != nil {
+		return err
+	}
+
+	err = m.collectParametersInfo(values)
+	if err != nil {
+		return err
+	}
+
+	// Collect runtime-specific configuration
+	return m.collectRuntimeData(values)
+}
+
+// collectGeneralInfo collects the action title, description, aliases, runtime and ID
+func (m *metadataCollector) collectGeneralInfo(values *templateValues) error {
+	// Prefill aliases, the form may be shown again from the summary screen.
+	aliasesStr := strings.Join(values.Action.Aliases, ", ")
+	err := m.runForm(
 		huh.NewGroup(
 			huh.NewInput().
 				Title("Title").
@@ -99,6 +241,14 @@ func (m *metadataCollector) attachInteractiveForm(values *templateValues) error
 				Title("Aliases").
 				Description("Comma-separated list of alternative names").
 				Placeholder("myaction, ma").
+				Validate(func(str string) error {
+					collisions := m.findCollisions(sanitizeForPath(values.ID), parseAliases(str), values.Runtime.Type)
+					if len(collisions) > 0 {
+						return &collisionError{collisions: collisions}
+					}
+
+					return nil
+				}).
 				Value(&aliasesStr),
 			huh.NewSelect[action.DefRuntimeType]().
 				Title("Runtime").
@@ -110,7 +260,7 @@ func (m *metadataCollector) attachInteractiveForm(values *templateValues) error
 				).
 				Value(&values.Runtime.Type),
 		),
-		huh.NewGroup(
+		m.hideGroup(huh.NewGroup(
 			huh.NewSelect[string]().
 				Title("Working directory").
 				Description("Runtime type for the action").
@@ -121,13 +271,21 @@ func (m *metadataCollector) attachInteractiveForm(values *templateValues) error
 				Value(&values.WD),
 			huh.NewSelect[string]().
 				Title("- Choose container files preset").
-				Options(
-					huh.NewOption("Golang", "go"),
-					huh.NewOption("Python", "py"),
-					huh.NewOption("Shell", "sh"),
-				).
+				Options(presetOptions(m.templates.presets.forRuntime(runtimeContainer))...).
+				Value(&values.ContainerPreset),
+		), func() bool { return values.Runtime.Type != runtimeContainer }),
+		m.hideGroup(huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Choose files preset").
+				OptionsFunc(func() []huh.Option[string] {
+					options := []huh.Option[string]{huh.NewOption("Default", "")}
+					return append(options, presetOptions(m.templates.presets.forRuntime(values.Runtime.Type))...)
+				}, &values.Runtime.Type).
 				Value(&values.ContainerPreset),
-		).WithHideFunc(func() bool { return values.Runtime.Type != runtimeContainer }),
+		), func() bool {
+			// Only contributed presets exist for other runtimes.
+			return values.Runtime.Type == runtimeContainer || len(m.templates.presets.forRuntime(values.Runtime.Type)) == 0
+		}),
 
 		huh.NewGroup(
 			huh.NewInput().
@@ -140,50 +298,38 @@ func (m *metadataCollector) attachInteractiveForm(values *templateValues) error
 						return err
 					}
 
-					safeID := sanitizeForPath(str)
-					_, ok := m.actionManager.Get(safeID)
-					if ok {
-						return fmt.Errorf("action with ID '%s' already exists", safeID)
-					}
-
-					return nil
+					return m.checkCollisions(sanitizeForPath(str), parseAliases(aliasesStr), values.Runtime.Type)
 				}).
 				Value(&values.ID),
 		),
 	)
-
-	err := form.Run()
 	if err != nil {
 		return err
 	}
 
-	// Parse aliases
-	if aliasesStr != "" {
-		aliases := strings.Split(aliasesStr, ",")
-		aliases = slices.Compact(aliases)
-		for _, alias := range aliases {
-			values.Action.Aliases = append(values.Action.Aliases, strings.TrimSpace(alias))
-		}
-	}
+	values.Action.Aliases = append([]string{}, parseAliases(aliasesStr)...)
 
+	return nil
+}
+
+// collectParametersInfo collects arguments and options and shows them for a review
+func (m *metadataCollector) collectParametersInfo(values *templateValues) error {
 	// Collect arguments
 	addArgs := false
 	// Ask if the user wants to add more parameters
-	form = huh.NewForm(
+	err := m.runForm(
 		huh.NewGroup(
 			huh.NewConfirm().
 				Title("Would you like to add arguments?").
 				Value(&addArgs),
 		),
 	)
-
-	err = form.Run()
 	if err != nil {
 		return fmt.Errorf("form error: %w", err)
 	}
 
 	if addArgs {
-		err = m.collectParameters("Arguments", &values.Action.Arguments)
+		err = m.collectParameters("Arguments", values, &values.Action.Arguments)
 		if err != nil {
 			return err
 		}
@@ -192,200 +338,115 @@ func (m *metadataCollector) attachInteractiveForm(values *templateValues) error
 	// Collect options
 	addOpts := false
 	// Ask if the user wants to add more parameters
-	form = huh.NewForm(
+	err = m.runForm(
 		huh.NewGroup(
 			huh.NewConfirm().
 				Title("Would you like to add options?").
 				Value(&addOpts),
 		),
 	)
-
-	err = form.Run()
 	if err != nil {
 		return fmt.Errorf("form error: %w", err)
 	}
 
 	if addOpts {
-		err = m.collectParameters("Options", &values.Action.Options)
+		err = m.collectParameters("Options", values, &values.Action.Options)
 		if err != nil {
 			return err
 		}
 	}
 
-	// Collect runtime-specific configuration
-	err = m.collectRuntimeData(values)
-	if err != nil {
-		return err
+	// Review collected parameters, fixing a typo shouldn't require starting over.
+	if len(values.Action.Arguments)+len(values.Action.Options) > 0 {
+		return m.reviewParameters(values)
 	}
 
-	return err
+	return nil
 }
 
 func (m *metadataCollector) collectRuntimeData(values *templateValues) error {
 	switch values.Runtime.Type {
 	case runtimeContainer:
-		container, err := m.collectContainerConfig()
+		container, err := m.collectContainerConfig(values)
 		if err != nil {
 			return err
 		}
 		values.Runtime.Container = container
+		// The image is derived again if the field is left empty.
+		values.derived.image = false
 	case runtimeShell:
-		shell, err := m.collectShellConfig()
+		shell, err := m.collectShellConfig(values)
 		if err != nil {
 			return err
 		}
 		values.Runtime.Shell = shell
+	case runtimePlugin:
+		return m.collectPluginConfig(values)
 	}
 
 	return nil
 }
 
-// collectParameters collects parameters (arguments or options)
-func (m *metadataCollector) collectParameters(paramType string, params *action.ParametersList) error {
-	var addMore = true
-
-	for addMore {
-		param := &action.DefParameter{
-			Items: &action.DefArrayItems{Type: jsonschema.String},
-		}
-		var defaultStr string
+// collectPluginConfig collects services the plugin gets from the application
+func (m *metadataCollector) collectPluginConfig(values *templateValues) error {
+	names := serviceNames(values.Services)
+	options := make([]huh.Option[string], 0, len(launchrServices))
+	for _, s := range launchrServices {
+		options = append(options, huh.NewOption(s.Title, s.Name).Selected(slices.Contains(names, s.Name)))
+	}
 
-		form := huh.NewForm(
-			huh.NewGroup(
-				huh.NewInput().
-					Title("Parameter Name").
-					Description(fmt.Sprintf("Name for this %s", paramType)).
-					Placeholder("name").
-					Value(&param.Name).
-					Validate(func(str string) error {
-						if str == "" {
-							return errors.New("name can't be empty")
-						}
-
-						err := isValidName("parameter", str)
-						if err != nil {
-							return err
-						}
-
-						for _, p := range *params {
-							if p.Name == str {
-								return fmt.Errorf("parameter with name '%s' already exists", str)
-							}
-						}
+	err := m.runForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Services").
+				Description("Services the plugin gets from the application, usage examples are added to the action").
+				Options(options...).
+				Value(&names),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("form error: %w", err)
+	}
 
-						return nil
-					}),
-				huh.NewInput().
-					Title("Title").
-					Description("Human-readable title").
-					Placeholder("Name").
-					Value(&param.Title),
-				huh.NewText().
-					Title("Description").
-					Description("Detailed description").
-					Lines(2).
-					Placeholder("The name of...").
-					Value(&param.Description),
-				huh.NewSelect[jsonschema.Type]().
-					Title("Type").
-					Description("Data type for this parameter").
-					Options(
-						huh.NewOption("String", jsonschema.String),
-						huh.NewOption("Number", jsonschema.Number),
-						huh.NewOption("Integer", jsonschema.Integer),
-						huh.NewOption("Boolean", jsonschema.Boolean),
-						huh.NewOption("Array", jsonschema.Array),
-					).
-					Value(&param.Type),
-				huh.NewSelect[bool]().
-					Title("Required").
-					Description("Is this parameter required?").
-					Options(
-						huh.NewOption("Yes", true),
-						huh.NewOption("No", false),
-					).
-					Value(&param.Required),
-			),
-			huh.NewGroup(
-				huh.NewSelect[jsonschema.Type]().
-					Title("Items Type").
-					Description("Data type of array items").
-					Options(
-						huh.NewOption("String", jsonschema.String),
-						huh.NewOption("Number", jsonschema.Number),
-						huh.NewOption("Integer", jsonschema.Integer),
-						huh.NewOption("Boolean", jsonschema.Boolean),
-					).
-					Value(&param.Items.Type),
-				huh.NewInput().
-					Title("Default Value (optional)").
-					Validate(func(v string) error {
-						if v == "" {
-							// do not validate an empty string.
-							return nil
-						}
-						_, err := castParamStrToType(v, param)
-						return err
-					}).
-					Value(&defaultStr),
-			).WithHideFunc(func() bool { return param.Type != jsonschema.Array }),
-			huh.NewGroup(
-				huh.NewInput().
-					Title("Default Value (optional)").
-					Validate(func(v string) error {
-						if v == "" {
-							// do not validate an empty string.
-							return nil
-						}
-						_, err := castParamStrToType(v, param)
-						return err
-					}).
-					Value(&defaultStr),
-			).WithHideFunc(func() bool { return param.Type == jsonschema.Array }),
-		)
+	values.Services, err = findLaunchrServices(names)
+	return err
+}
 
-		err := form.Run()
-		if err != nil {
-			return fmt.Errorf("form error: %w", err)
+// presetOptions creates form options for presets
+func presetOptions(presets []*Preset) []huh.Option[string] {
+	options := make([]huh.Option[string], 0, len(presets))
+	for _, p := range presets {
+		label := p.Name
+		if p.Description != "" {
+			label = p.Description
 		}
+		options = append(options, huh.NewOption(label, p.Name))
+	}
 
-		// Set default value if provided
-		if defaultStr != "" {
-			param.Default, err = castParamStrToType(defaultStr, param)
-			if err != nil {
-				return err
-			}
-		} else {
-			param.Default, err = jsonschema.EnsureType(param.Type, nil)
-			if err != nil {
-				return err
-			}
-			// explicitly set the number as '0.0' as otherwise there will be an action definition error.
-			if param.Type == jsonschema.Number {
-				param.Default = "0.0"
-			}
-		}
+	return options
+}
 
-		if param.Type != jsonschema.Array {
-			param.Items = nil
-		}
+// collectParameters collects parameters (arguments or options)
+func (m *metadataCollector) collectParameters(paramType string, values *templateValues, params *action.ParametersList) error {
+	var addMore = true
 
-		// Normalize parameter name
-		param.Name = strings.ToLower(param.Name)
+	for addMore {
+		param, err := m.runParameterForm(paramType, values, nil)
+		if err != nil {
+			return err
+		}
 
 		// Add parameter to list
 		*params = append(*params, param)
 
 		// Ask if the user wants to add more parameters
-		form = huh.NewForm(
+		err = m.runForm(
 			huh.NewGroup(
 				huh.NewConfirm().
 					Title(fmt.Sprintf("Add another %s?", paramType)).
 					Value(&addMore),
 			),
 		)
-
-		err = form.Run()
 		if err != nil {
 			return fmt.Errorf("form error: %w", err)
 		}
@@ -394,28 +455,241 @@ func (m *metadataCollector) collectParameters(paramType string, params *action.P
 	return nil
 }
 
+// runParameterForm asks for parameter fields, the form is prefilled with fields of an edited parameter
+func (m *metadataCollector) runParameterForm(paramType string, values *templateValues, edited *action.DefParameter) (*action.DefParameter, error) {
+	editedName := ""
+	if edited != nil {
+		editedName = edited.Name
+	}
+
+	param := &action.DefParameter{
+		Items: &action.DefArrayItems{Type: jsonschema.String},
+	}
+	var defaultStr, enumStr string
+	constraintStrs := make(map[string]*string, len(constraintKeys))
+	for _, key := range constraintKeys[1:] {
+		constraintStrs[key] = new(string)
+	}
+	if edited != nil {
+		// Work on a copy, the edited parameter stays untouched until the form is valid.
+		*param = *edited
+		if edited.Items != nil {
+			param.Items = &action.DefArrayItems{Type: edited.Items.Type}
+		} else {
+			param.Items = &action.DefArrayItems{Type: jsonschema.String}
+		}
+		defaultStr = formatParamValue(edited.Default)
+		enumStr = formatEnum(edited.Enum)
+		c := values.Constraints[edited.Name]
+		for key, v := range constraintStrs {
+			*v = c.get(key)
+		}
+	}
+	// constraints parses constraints entered so far, they are validated by the fields.
+	constraints := func() *paramConstraints {
+		c := &paramConstraints{}
+		for key, v := range constraintStrs {
+			if *v != "" {
+				_ = c.set(key, *v)
+			}
+		}
+		return c
+	}
+	validateDefault := func(v string) error {
+		if v == "" {
+			// do not validate an empty string.
+			return nil
+		}
+		val, err := castParamStrToType(v, param)
+		if err != nil {
+			return err
+		}
+
+		param.Enum = parseEnum(enumStr)
+		c := constraints()
+		err = checkConstraints(param, c)
+		if err != nil {
+			return err
+		}
+		return checkConstrainedValue(param, c, val)
+	}
+
+	err := m.runForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Parameter Name").
+				Description(fmt.Sprintf("Name for this %s", paramType)).
+				Placeholder("name").
+				Value(&param.Name).
+				Validate(func(str string) error {
+					if str == "" {
+						return errors.New("name can't be empty")
+					}
+
+					err := isValidName("parameter", str)
+					if err != nil {
+						return err
+					}
+
+					if str != editedName && values.hasParameter(str) {
+						return fmt.Errorf("parameter with name '%s' already exists", str)
+					}
+
+					return nil
+				}),
+			huh.NewInput().
+				Title("Title").
+				Description("Human-readable title").
+				Placeholder("Name").
+				Value(&param.Title),
+			huh.NewText().
+				Title("Description").
+				Description("Detailed description").
+				Lines(2).
+				Placeholder("The name of...").
+				Value(&param.Description),
+			huh.NewSelect[jsonschema.Type]().
+				Title("Type").
+				Description("Data type for this parameter").
+				Options(
+					huh.NewOption("String", jsonschema.String),
+					huh.NewOption("Number", jsonschema.Number),
+					huh.NewOption("Integer", jsonschema.Integer),
+					huh.NewOption("Boolean", jsonschema.Boolean),
+					huh.NewOption("Array", jsonschema.Array),
+				).
+				Value(&param.Type),
+			huh.NewSelect[bool]().
+				Title("Required").
+				Description("Is this parameter required?").
+				Options(
+					huh.NewOption("Yes", true),
+					huh.NewOption("No", false),
+				).
+				Value(&param.Required),
+		),
+		m.hideGroup(huh.NewGroup(
+			huh.NewInput().
+				Title("Allowed Values (optional)").
+				Description("Values separated by |").
+				Placeholder("a|b|c").
+				Value(&enumStr),
+		), func() bool { return param.Type == jsonschema.Array || param.Type == jsonschema.Boolean }),
+		m.hideGroup(huh.NewGroup(
+			constraintInput("pattern", "Pattern (optional)", "Regular expression the value must match", constraintStrs),
+			constraintInput("minLength", "Min Length (optional)", "Minimum number of characters", constraintStrs),
+			constraintInput("maxLength", "Max Length (optional)", "Maximum number of characters", constraintStrs),
+			huh.NewSelect[string]().
+				Title("Format (optional)").
+				Description("Format the value must conform to").
+				Options(append([]huh.Option[string]{huh.NewOption("None", "")}, huh.NewOptions(constraintFormats...)...)...).
+				Value(constraintStrs["format"]),
+		), func() bool { return param.Type != jsonschema.String }),
+		m.hideGroup(huh.NewGroup(
+			constraintInput("minimum", "Minimum (optional)", "Minimum value", constraintStrs),
+			constraintInput("maximum", "Maximum (optional)", "Maximum value", constraintStrs),
+		), func() bool { return param.Type != jsonschema.Number && param.Type != jsonschema.Integer }),
+		m.hideGroup(huh.NewGroup(
+			constraintInput("minItems", "Min Items (optional)", "Minimum number of items", constraintStrs),
+			constraintInput("maxItems", "Max Items (optional)", "Maximum number of items", constraintStrs),
+		), func() bool { return param.Type != jsonschema.Array }),
+		m.hideGroup(huh.NewGroup(
+			huh.NewSelect[jsonschema.Type]().
+				Title("Items Type").
+				Description("Data type of array items").
+				Options(
+					huh.NewOption("String", jsonschema.String),
+					huh.NewOption("Number", jsonschema.Number),
+					huh.NewOption("Integer", jsonschema.Integer),
+					huh.NewOption("Boolean", jsonschema.Boolean),
+				).
+				Value(&param.Items.Type),
+			huh.NewInput().
+				Title("Default Value (optional)").
+				Validate(validateDefault).
+				Value(&defaultStr),
+		), func() bool { return param.Type != jsonschema.Array }),
+		m.hideGroup(huh.NewGroup(
+			huh.NewInput().
+				Title("Default Value (optional)").
+				Validate(validateDefault).
+				Value(&defaultStr),
+		), func() bool { return param.Type == jsonschema.Array }),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("form error: %w", err)
+	}
+
+	// Set default value if provided, an empty field means there is no default.
+	param.Default = nil
+	if defaultStr != "" {
+		param.Default = defaultStr
+	}
+
+	err = normalizeParameter(param)
+	if err != nil {
+		return nil, err
+	}
+
+	param.Enum = parseEnum(enumStr)
+	c := constraints()
+	err = normalizeConstraints(param, c)
+	if err != nil {
+		return nil, err
+	}
+	err = fillDefault(param, c)
+	if err != nil {
+		return nil, err
+	}
+	values.setConstraints(editedName, nil)
+	values.setConstraints(param.Name, c)
+
+	return param, nil
+}
+
+// constraintInput creates a form field of a constraint entered as a string
+func constraintInput(key, title, description string, values map[string]*string) *huh.Input {
+	return huh.NewInput().
+		Title(title).
+		Description(description).
+		Validate(func(v string) error {
+			if v == "" {
+				return nil
+			}
+			return (&paramConstraints{}).set(key, v)
+		}).
+		Value(values[key])
+}
+
 // collectContainerConfig collects container-specific configuration
-func (m *metadataCollector) collectContainerConfig() (*action.DefRuntimeContainer, error) {
-	config := &action.DefRuntimeContainer{
-		Env: make(action.EnvSlice, 0),
+func (m *metadataCollector) collectContainerConfig(values *templateValues) (*action.DefRuntimeContainer, error) {
+	// Start from the current configuration, so the command and build aren't lost.
+	config := *values.Runtime.Container
+	config.Env = make(action.EnvSlice, 0)
+	config.ExtraHosts = nil
+	// A derived image is shown as the placeholder, so it's still derived if the field is left empty.
+	if values.derived.image {
+		config.Image = ""
 	}
 
-	var envStr string
-	var extraHostsStr string
+	// Prefill values passed as options or loaded from a file.
+	envStr := strings.Join(values.Runtime.Container.Env, "\n")
+	extraHostsStr := strings.Join(values.Runtime.Container.ExtraHosts, ", ")
+	derivedImage := m.config.Image.imageFor(values.ID)
 
-	form := huh.NewForm(
+	err := m.runForm(
 		huh.NewGroup(
 			huh.NewInput().
 				Title("Image").
-				Description("Docker image to use").
-				Placeholder("actionid:latest").
+				Description(fmt.Sprintf("Docker image to use, leave empty for %s", derivedImage)).
+				Placeholder(derivedImage).
 				Value(&config.Image).
 				Validate(func(str string) error {
 					if str == "" {
-						return errors.New("image can't be empty")
+						return nil
 					}
 
-					return nil
+					return validateImageRef(str)
 				}),
 			huh.NewText().
 				Title("Environment Variables").
@@ -427,8 +701,6 @@ func (m *metadataCollector) collectContainerConfig() (*action.DefRuntimeContaine
 				Value(&extraHostsStr),
 		),
 	)
-
-	err := form.Run()
 	if err != nil {
 		return nil, fmt.Errorf("form error: %w", err)
 	}
@@ -457,18 +729,20 @@ func (m *metadataCollector) collectContainerConfig() (*action.DefRuntimeContaine
 		}
 	}
 
-	return config, nil
+	return &config, nil
 }
 
 // collectShellConfig collects shell-specific configuration
-func (m *metadataCollector) collectShellConfig() (*action.DefRuntimeShell, error) {
+func (m *metadataCollector) collectShellConfig(values *templateValues) (*action.DefRuntimeShell, error) {
 	config := &action.DefRuntimeShell{
-		Env: make(action.EnvSlice, 0),
+		Env:    make(action.EnvSlice, 0),
+		Script: values.Runtime.Shell.Script,
 	}
 
-	var envStr string
+	// Prefill variables passed as options or shown again from the summary screen.
+	envStr := strings.Join(values.Runtime.Shell.Env, "\n")
 
-	form := huh.NewForm(
+	err := m.runForm(
 		huh.NewGroup(
 			huh.NewText().
 				Title("Environment Variables").
@@ -476,8 +750,6 @@ func (m *metadataCollector) collectShellConfig() (*action.DefRuntimeShell, error
 				Value(&envStr),
 		),
 	)
-
-	err := form.Run()
 	if err != nil {
 		return nil, fmt.Errorf("form error: %w", err)
 	}
@@ -501,6 +773,73 @@ func (m *metadataCollector) collectShellConfig() (*action.DefRuntimeShell, error
 	return config, nil
 }
 
+// normalizeParameter converts a collected parameter default to the declared type and cleans up unused fields
+func normalizeParameter(param *action.DefParameter) error {
+	var err error
+	if param.Type == "" {
+		param.Type = jsonschema.String
+	}
+	if param.Type == jsonschema.Array && param.Items == nil {
+		param.Items = &action.DefArrayItems{Type: jsonschema.String}
+	}
+
+	// A default that isn't given stays nil, see [fillDefault].
+	switch v := param.Default.(type) {
+	case string:
+		if param.Type != jsonschema.String {
+			param.Default, err = castParamStrToType(v, param)
+			if err != nil {
+				return fmt.Errorf("invalid default value for parameter '%s': %w", param.Name, err)
+			}
+		}
+	case []any:
+		if param.Type != jsonschema.Array {
+			return fmt.Errorf("invalid default value for parameter '%s': list given for type %s", param.Name, param.Type)
+		}
+	}
+
+	// Keep numbers as floats, they are written with a fraction as otherwise there will be an action definition error.
+	if v, ok := param.Default.(int); ok && param.Type == jsonschema.Number {
+		param.Default = float64(v)
+	}
+
+	if param.Type != jsonschema.Array {
+		param.Items = nil
+	}
+
+	// Normalize parameter name
+	param.Name = strings.ToLower(param.Name)
+
+	return nil
+}
+
+// formatParamValue formats a parameter value the way it's entered in the form, the reverse of [castParamStrToType]
+func formatParamValue(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	if items, ok := v.([]any); ok {
+		res := make([]string, len(items))
+		for i, item := range items {
+			res[i] = fmt.Sprint(item)
+		}
+		return strings.Join(res, ",")
+	}
+
+	return fmt.Sprint(v)
+}
+
+// formatEnum formats enum values the way they're entered in the form, the reverse of [parseEnum]
+func formatEnum(enum []any) string {
+	res := make([]string, len(enum))
+	for i, e := range enum {
+		res[i] = fmt.Sprint(e)
+	}
+
+	return strings.Join(res, "|")
+}
+
 func castParamStrToType(v string, pdef *action.DefParameter) (any, error) {
 	var err error
 	if pdef.Type != jsonschema.Array {
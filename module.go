@@ -0,0 +1,225 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/launchrctl/launchr"
+	"golang.org/x/mod/modfile"
+)
+
+// launchrImportPath is imported by a file registering plugins of a launchr application
+const launchrImportPath = "github.com/launchrctl/launchr"
+
+// pluginsFileName is a file of the module root dedicated to blank imports of plugins
+const pluginsFileName = "plugins.go"
+
+// appMainCandidates are main files registering plugins by blank imports if there is no plugins file,
+// relative to the module root
+var appMainCandidates = []string{"cmd/launchr/main.go", "cmd/*/main.go", "main.go"}
+
+// goModule is a Go module enclosing a generated plugin
+type goModule struct {
+	dir  string // Module root containing go.mod
+	path string // Module path declared in go.mod
+}
+
+// findGoModule looks for go.mod in the directory and its parents, nil is returned if there is none
+func findGoModule(dir string) (*goModule, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		data, err := os.ReadFile(filepath.Clean(filepath.Join(dir, "go.mod")))
+		if err == nil {
+			modPath := modfile.ModulePath(data)
+			if modPath == "" {
+				return nil, fmt.Errorf("module path is not declared in %s", filepath.Join(dir, "go.mod"))
+			}
+			return &goModule{dir: dir, path: modPath}, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read go.mod: %w", err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// importPath returns the import path of a package directory inside the module
+func (m *goModule) importPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(m.dir, dir)
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(m.path, filepath.ToSlash(rel)), nil
+}
+
+// findPluginsFile finds the plugins file or a main file of the module importing launchr, empty if there is none
+func (m *goModule) findPluginsFile() (string, error) {
+	pluginsFile := filepath.Join(m.dir, pluginsFileName)
+	_, err := os.Stat(pluginsFile)
+	if err == nil {
+		return pluginsFile, nil
+	}
+
+	for _, pattern := range appMainCandidates {
+		matches, err := filepath.Glob(filepath.Join(m.dir, pattern))
+		if err != nil {
+			return "", err
+		}
+
+		for _, f := range matches {
+			file, err := parser.ParseFile(token.NewFileSet(), f, nil, parser.ImportsOnly)
+			if err != nil {
+				return "", fmt.Errorf("failed to parse %s: %w", f, err)
+			}
+			if hasImport(file, launchrImportPath) {
+				return f, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// hasImport checks if the file imports the path
+func hasImport(file *ast.File, importPath string) bool {
+	return slices.ContainsFunc(file.Imports, func(spec *ast.ImportSpec) bool {
+		p, err := strconv.Unquote(spec.Path.Value)
+		return err == nil && p == importPath
+	})
+}
+
+// addBlankImport adds a blank import of the path to a Go file, it returns false if the path is already imported
+func addBlankImport(filename, importPath string) (bool, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	if hasImport(file, importPath) {
+		return false, nil
+	}
+
+	// Put the import next to other blank imports, the last import declaration is used otherwise.
+	var decl *ast.GenDecl
+	for _, d := range file.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if decl == nil || hasBlankImport(gen) || !hasBlankImport(decl) {
+			decl = gen
+		}
+	}
+	if decl == nil {
+		return false, fmt.Errorf("%s doesn't have an import declaration to add the plugin to", filename)
+	}
+
+	// The new spec takes the position of the last one, so it stays in the same group of imports.
+	pos := decl.Rparen
+	if len(decl.Specs) > 0 {
+		pos = decl.Specs[len(decl.Specs)-1].End()
+	}
+	spec := &ast.ImportSpec{
+		Name:   &ast.Ident{Name: "_", NamePos: pos},
+		Path:   &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(importPath), ValuePos: pos},
+		EndPos: pos,
+	}
+	if !decl.Lparen.IsValid() {
+		decl.Lparen = decl.Specs[0].Pos()
+		decl.Rparen = pos
+	}
+	decl.Specs = append(decl.Specs, spec)
+	ast.SortImports(fset, file)
+
+	var buf bytes.Buffer
+	err = format.Node(&buf, fset, file)
+	if err != nil {
+		return false, fmt.Errorf("failed to format %s: %w", filename, err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return false, err
+	}
+
+	return true, os.WriteFile(filename, buf.Bytes(), info.Mode().Perm())
+}
+
+// hasBlankImport checks if the import declaration contains blank imports
+func hasBlankImport(decl *ast.GenDecl) bool {
+	return slices.ContainsFunc(decl.Specs, func(s ast.Spec) bool {
+		spec, ok := s.(*ast.ImportSpec)
+		return ok && spec.Name != nil && spec.Name.Name == "_"
+	})
+}
+
+// integratePlugin reports the import path of a generated plugin and registers it in the application
+// if requested, the plugin is built into the application on the next build
+func integratePlugin(pluginDir string, register, dryRun bool) error {
+	mod, err := findGoModule(pluginDir)
+	if err != nil {
+		return err
+	}
+	if mod == nil {
+		launchr.Term().Warning().Printfln("Go module enclosing %s is not found, add the plugin to a module to build it into the application", pluginDir)
+		return nil
+	}
+
+	importPath, err := mod.importPath(pluginDir)
+	if err != nil {
+		return err
+	}
+	launchr.Term().Info().Printfln("Plugin package is %s", importPath)
+
+	pluginsFile, err := mod.findPluginsFile()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case pluginsFile == "":
+		launchr.Term().Info().Printfln("Import it in the application main file to build the plugin in: _ %q", importPath)
+	case !register:
+		launchr.Term().Info().Printfln("Add _ %q to %s or pass --register to do it on generation", importPath, pluginsFile)
+	case dryRun:
+		launchr.Term().Info().Printfln("Import _ %q would be added to %s", importPath, pluginsFile)
+	default:
+		added, err := addBlankImport(pluginsFile, importPath)
+		if err != nil {
+			return fmt.Errorf("failed to register the plugin: %w", err)
+		}
+		if !added {
+			launchr.Term().Info().Printfln("Plugin is already registered in %s", pluginsFile)
+			break
+		}
+		launchr.Term().Success().Printfln("Plugin is registered in %s, it's available after the next build", pluginsFile)
+	}
+
+	return nil
+}
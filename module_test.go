@@ -0,0 +1,125 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddBlankImport(t *testing.T) {
+	const importPath = "example.com/app/plugins/myaction"
+	tests := []struct {
+		name      string
+		src       string
+		want      string // Content of the file after the call
+		wantAdded bool
+		wantErr   bool
+	}{
+		{
+			name: "group with blank imports",
+			src: `package main
+
+import (
+	"github.com/launchrctl/launchr"
+
+	_ "example.com/app/plugins/build"
+	_ "github.com/launchrctl/compose"
+)
+
+func main() {
+	launchr.RunAndExit()
+}
+`,
+			want: `package main
+
+import (
+	"github.com/launchrctl/launchr"
+
+	_ "example.com/app/plugins/build"
+	_ "example.com/app/plugins/myaction"
+	_ "github.com/launchrctl/compose"
+)
+
+func main() {
+	launchr.RunAndExit()
+}
+`,
+			wantAdded: true,
+		},
+		{
+			name: "separate blank imports declaration",
+			src: `package main
+
+import "github.com/launchrctl/launchr"
+
+import (
+	_ "github.com/launchrctl/compose"
+)
+`,
+			want: `package main
+
+import "github.com/launchrctl/launchr"
+
+import (
+	_ "example.com/app/plugins/myaction"
+	_ "github.com/launchrctl/compose"
+)
+`,
+			wantAdded: true,
+		},
+		{
+			name:      "empty group",
+			src:       "package plugins\n\nimport ()\n",
+			want:      "package plugins\n\nimport (\n\t_ \"example.com/app/plugins/myaction\"\n)\n",
+			wantAdded: true,
+		},
+		{
+			name:      "single import",
+			src:       "package main\n\nimport \"github.com/launchrctl/launchr\"\n\nfunc main() {\n\tlaunchr.RunAndExit()\n}\n",
+			want:      "package main\n\nimport (\n\t_ \"example.com/app/plugins/myaction\"\n\t\"github.com/launchrctl/launchr\"\n)\n\nfunc main() {\n\tlaunchr.RunAndExit()\n}\n",
+			wantAdded: true,
+		},
+		{
+			name: "already imported",
+			src:  "package plugins\n\nimport (\n\t_ \"example.com/app/plugins/myaction\"\n)\n",
+			want: "package plugins\n\nimport (\n\t_ \"example.com/app/plugins/myaction\"\n)\n",
+		},
+		{
+			name:    "no import declaration",
+			src:     "package plugins\n",
+			want:    "package plugins\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid source",
+			src:     "package plugins\n\nimport (\n",
+			want:    "package plugins\n\nimport (\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "plugins.go")
+			if err := os.WriteFile(filename, []byte(tt.src), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			added, err := addBlankImport(filename, importPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addBlankImport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if added != tt.wantAdded {
+				t.Errorf("addBlankImport() = %v, want %v", added, tt.wantAdded)
+			}
+
+			got, err := os.ReadFile(filepath.Clean(filename))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file after addBlankImport():\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
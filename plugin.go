@@ -2,9 +2,13 @@
 package scaffold
 
 import (
+	"cmp"
 	"context"
 	_ "embed"
-	"path/filepath"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
 
 	"github.com/launchrctl/launchr"
 	"github.com/launchrctl/launchr/pkg/action"
@@ -13,13 +17,30 @@ import (
 //go:embed action.yaml
 var actionYaml []byte
 
+//go:embed action.templates.yaml
+var actionTemplatesYaml []byte
+
+//go:embed action.templates.check.yaml
+var actionTemplatesCheckYaml []byte
+
+//go:embed action.app.yaml
+var actionAppYaml []byte
+
+//go:embed action.plugin-repo.yaml
+var actionPluginRepoYaml []byte
+
 func init() {
 	launchr.RegisterPlugin(&Plugin{})
 }
 
 // Plugin is [launchr.Plugin] providing scaffold functionality.
 type Plugin struct {
-	m action.Manager
+	m       action.Manager
+	root    *launchr.Command
+	cfg     launchr.Config
+	wd      string
+	appName string
+	presets *presetRegistry
 }
 
 // PluginInfo implements [launchr.Plugin] interface.
@@ -32,6 +53,21 @@ func (p *Plugin) PluginInfo() launchr.PluginInfo {
 // OnAppInit implements [launchr.OnAppInitPlugin] interface.
 func (p *Plugin) OnAppInit(app launchr.App) error {
 	app.GetService(&p.m)
+	app.GetService(&p.cfg)
+	p.wd = app.GetWD()
+	p.appName = app.Name()
+
+	var pm launchr.PluginManager
+	app.GetService(&pm)
+	p.presets = newPresetRegistry()
+	p.presets.discover(pm.All())
+	return nil
+}
+
+// CobraAddCommands implements [launchr.CobraPlugin] interface.
+// The root command is kept to check names of new actions against commands of launchr.
+func (p *Plugin) CobraAddCommands(root *launchr.Command) error {
+	p.root = root
 	return nil
 }
 
@@ -39,7 +75,26 @@ func (p *Plugin) OnAppInit(app launchr.App) error {
 func (p *Plugin) DiscoverActions(_ context.Context) ([]*action.Action, error) {
 	_ = action.Definition{}
 
-	a := action.NewFromYAML("scaffold", actionYaml)
+	if p.presets == nil {
+		p.presets = newPresetRegistry()
+	}
+
+	scaffold, err := p.newScaffoldAction()
+	if err != nil {
+		return nil, err
+	}
+
+	return []*action.Action{scaffold, p.newTemplatesAction(), p.newTemplatesCheckAction(), p.newAppAction(), p.newPluginRepoAction()}, nil
+}
+
+// newScaffoldAction creates the action generating a new action
+func (p *Plugin) newScaffoldAction() (*action.Action, error) {
+	def, err := withPresetsEnum(actionYaml, p.presets.names())
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare scaffold definition: %w", err)
+	}
+
+	a := action.NewFromYAML("scaffold", def)
 	a.SetRuntime(action.NewFnRuntime(func(_ context.Context, a *action.Action) error {
 		outputDir := a.Input().Opt("output").(string)
 		runtimeType := a.Input().Opt("runtime").(string)
@@ -47,34 +102,276 @@ func (p *Plugin) DiscoverActions(_ context.Context) ([]*action.Action, error) {
 		title := a.Input().Opt("title").(string)
 		containerPreset := a.Input().Opt("preset").(string)
 		interactive := a.Input().Opt("interactive").(bool)
-		interactive = interactive && a.Input().Streams() != nil && a.Input().Streams().In().IsTerminal()
+		fromFile := a.Input().Opt("from-file").(string)
+		replay := a.Input().Opt("replay").(string)
+		if fromFile != "" && replay != "" {
+			return fmt.Errorf("options --from-file and --replay can't be used together")
+		}
+		dryRun := a.Input().Opt("dry-run").(bool)
+		register := a.Input().Opt("register").(bool)
+		conflict, err := newConflictMode(a.Input().Opt("force").(bool), a.Input().Opt("merge").(bool))
+		if err != nil {
+			return err
+		}
+		prompt := newPromptOptions(interactive, a.Input().Opt("accessible").(bool), a.Input().Streams())
+
+		flags, err := newFlagsSpec(a.Input())
+		if err != nil {
+			return err
+		}
+
+		config, err := loadScaffoldConfig(p.cfg)
+		if err != nil {
+			return err
+		}
+
+		templatesFS, err := p.templatesFS(a.Input().Opt("templates").(string))
+		if err != nil {
+			return err
+		}
 
 		scaffold := scaffoldAction{
 			manager:         p.m,
+			rootCmd:         p.root,
+			config:          config,
+			templates:       templatesFS,
+			presets:         p.presets,
 			outputDir:       outputDir,
 			runtime:         action.DefRuntimeType(runtimeType),
 			id:              id,
 			title:           title,
 			containerPreset: containerPreset,
-			interactive:     interactive,
+			prompt:          prompt,
+			fromFile:        fromFile,
+			replay:          replay,
+			dryRun:          dryRun,
+			register:        register,
+			conflict:        conflict,
+			flags:           flags,
+			changed:         make(map[string]bool),
+		}
+		for _, name := range []string{"id", "title", "runtime", "preset"} {
+			scaffold.changed[name] = a.Input().IsOptChanged(name)
 		}
 
 		return scaffold.run()
 	}))
 
-	return []*action.Action{a}, nil
+	return a, nil
+}
+
+// newTemplatesAction creates the action listing available templates
+func (p *Plugin) newTemplatesAction() *action.Action {
+	a := action.NewFromYAML("scaffold:templates", actionTemplatesYaml)
+	a.SetRuntime(action.NewFnRuntime(func(_ context.Context, a *action.Action) error {
+		format := a.Input().Opt("format").(string)
+		templatesFS, err := p.templatesFS(a.Input().Opt("templates").(string))
+		if err != nil {
+			return err
+		}
+
+		entries, err := newTemplateManager(templatesFS, p.presets).catalog()
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			return printCatalogJSON(a.Input().Streams().Out(), entries)
+		}
+
+		printCatalog(entries)
+		return nil
+	}))
+
+	return a
+}
+
+// newTemplatesCheckAction creates the action rendering all templates against synthetic values
+func (p *Plugin) newTemplatesCheckAction() *action.Action {
+	a := action.NewFromYAML("scaffold:templates:check", actionTemplatesCheckYaml)
+	a.SetRuntime(action.NewFnRuntime(func(_ context.Context, a *action.Action) error {
+		templatesFS, err := p.templatesFS(a.Input().Opt("templates").(string))
+		if err != nil {
+			return err
+		}
+
+		failures, total, err := newTemplateManager(templatesFS, p.presets).checkTemplates()
+		if err != nil {
+			return err
+		}
+
+		return printCheckFailures(failures, total)
+	}))
+
+	return a
+}
+
+// newAppAction creates the action generating a new launchr-based application
+func (p *Plugin) newAppAction() *action.Action {
+	a := action.NewFromYAML("scaffold:app", actionAppYaml)
+	a.SetRuntime(action.NewFnRuntime(func(_ context.Context, a *action.Action) error {
+		name := a.Input().Arg("name").(string)
+		values, err := newAppValues(name, a.Input().Opt("module").(string), a.Input().Opt("launchr-version").(string))
+		if err != nil {
+			return err
+		}
+
+		gen, err := p.newProjectGenerator(a.Input())
+		if err != nil {
+			return err
+		}
+
+		return gen.generateApp(cmp.Or(a.Input().Opt("output").(string), name), values)
+	}))
+
+	return a
+}
+
+// newPluginRepoAction creates the action generating a standalone plugin project
+func (p *Plugin) newPluginRepoAction() *action.Action {
+	a := action.NewFromYAML("scaffold:plugin-repo", actionPluginRepoYaml)
+	a.SetRuntime(action.NewFnRuntime(func(_ context.Context, a *action.Action) error {
+		name := a.Input().Arg("name").(string)
+		values, err := newPluginRepoValues(
+			name,
+			a.Input().Opt("module").(string),
+			a.Input().Opt("launchr-version").(string),
+			a.Input().Opt("weight").(int),
+			optStrings(a.Input().Opt("service")),
+		)
+		if err != nil {
+			return err
+		}
+
+		gen, err := p.newProjectGenerator(a.Input())
+		if err != nil {
+			return err
+		}
+
+		return gen.generatePluginRepo(cmp.Or(a.Input().Opt("output").(string), name), values)
+	}))
+
+	return a
+}
+
+// newProjectGenerator creates a generator of a project skeleton configured by the action options
+func (p *Plugin) newProjectGenerator(input *action.Input) (*generator, error) {
+	conflict, err := newConflictMode(input.Opt("force").(bool), input.Opt("merge").(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	templatesFS, err := p.templatesFS(input.Opt("templates").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	return newGenerator("", newTemplateManager(templatesFS, p.presets), generatorOptions{
+		dryRun:   input.Opt("dry-run").(bool),
+		conflict: conflict,
+	}), nil
+}
+
+// templatesFS returns templates layered from the given directory, project, user and embedded templates
+func (p *Plugin) templatesFS(dir string) (*layeredFS, error) {
+	sources, err := templateSources(dir, projectTemplatesDir(p.wd), userTemplatesDir(p.appName))
+	if err != nil {
+		return nil, err
+	}
+
+	return newLayeredFS(sources), nil
 }
 
 type scaffoldAction struct {
-	manager action.Manager
+	manager   action.Manager
+	rootCmd   *launchr.Command
+	config    *scaffoldConfig
+	templates fs.FS
+	presets   *presetRegistry
 
 	runtime action.DefRuntimeType
 	id      string
 	title   string
 
 	outputDir       string
-	interactive     bool
+	prompt          promptOptions
 	containerPreset string
+	fromFile        string
+	replay          string
+	dryRun          bool
+	register        bool // Add the generated plugin to the application plugins file
+	conflict        conflictMode
+
+	// flags holds the definition parts passed as options, they are applied on top of other values.
+	flags *actionSpec
+	// changed holds options explicitly passed on the command line,
+	// they take precedence over values loaded from a spec or answers file.
+	changed map[string]bool
+}
+
+// newFlagsSpec collects definition fields passed as options
+func newFlagsSpec(input *action.Input) (*actionSpec, error) {
+	spec := &actionSpec{
+		Description:      input.Opt("description").(string),
+		Aliases:          optStrings(input.Opt("alias")),
+		Image:            input.Opt("image").(string),
+		Env:              optStrings(input.Opt("env")),
+		ExtraHosts:       optStrings(input.Opt("extra-host")),
+		Services:         optStrings(input.Opt("service")),
+		WorkingDirectory: input.Opt("wd").(string),
+	}
+
+	for _, v := range optStrings(input.Opt("var")) {
+		name, val, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid variable %q, expected name=value", v)
+		}
+		if spec.Vars == nil {
+			spec.Vars = make(map[string]any)
+		}
+		spec.Vars[strings.TrimSpace(name)] = val
+	}
+
+	for _, v := range optStrings(input.Opt("arg")) {
+		ps, err := parseParamFlag(v)
+		if err != nil {
+			return nil, err
+		}
+		spec.Arguments = append(spec.Arguments, ps)
+	}
+
+	for _, v := range optStrings(input.Opt("opt")) {
+		ps, err := parseParamFlag(v)
+		if err != nil {
+			return nil, err
+		}
+		spec.Options = append(spec.Options, ps)
+	}
+
+	for _, v := range optStrings(input.Opt("constraint")) {
+		err := parseConstraintFlag(v, spec.Arguments, spec.Options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return spec, nil
+}
+
+// optStrings converts a repeatable option value to a list of strings
+func optStrings(v any) []string {
+	switch vv := v.(type) {
+	case []string:
+		return vv
+	case []any:
+		res := make([]string, 0, len(vv))
+		for _, item := range vv {
+			res = append(res, fmt.Sprint(item))
+		}
+		return res
+	default:
+		return nil
+	}
 }
 
 func (s *scaffoldAction) getDefaultValues() *templateValues {
@@ -95,28 +392,96 @@ func (s *scaffoldAction) getDefaultValues() *templateValues {
 		},
 		ID:              s.id,
 		ContainerPreset: s.containerPreset,
+		Vars:            make(map[string]any),
 	}
 
 	return v
 }
 
+// applyFlagOverrides puts options explicitly passed on the command line on top of values loaded from a file
+func (s *scaffoldAction) applyFlagOverrides(values *templateValues) {
+	if s.changed["id"] {
+		values.ID = s.id
+	}
+	if s.changed["title"] {
+		values.Action.Title = s.title
+	}
+	if s.changed["runtime"] {
+		values.Runtime.Type = s.runtime
+	}
+	if s.changed["preset"] {
+		values.ContainerPreset = s.containerPreset
+	}
+}
+
 // run runs the generator based on command-line arguments
 func (s *scaffoldAction) run() error {
 	defaults := s.getDefaultValues()
-	metadata := newMetadataCollector(s.manager, s.interactive)
+	specFile := s.fromFile
+	if s.replay != "" {
+		specFile = s.replay
+	}
+
+	if specFile != "" {
+		spec, err := loadActionSpec(specFile)
+		if err != nil {
+			return err
+		}
+
+		if s.replay != "" && spec.TemplateVersion != templatesVersion {
+			launchr.Term().Warning().Printfln("Answers were recorded with templates version %q, current version is %q", spec.TemplateVersion, templatesVersion)
+		}
+
+		err = spec.apply(defaults)
+		if err != nil {
+			return fmt.Errorf("invalid spec file %s: %w", specFile, err)
+		}
+		s.applyFlagOverrides(defaults)
+	}
+
+	err := s.flags.apply(defaults)
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	// Existing actions may be regenerated when overwriting is explicitly requested.
+	allowExisting := s.conflict != conflictFail
+	tmplManager := newTemplateManager(s.templates, s.presets)
+	metadata := newMetadataCollector(s.manager, s.rootCmd, s.config, tmplManager, s.prompt, s.outputDir, allowExisting)
 	values, err := metadata.collectActionInfo(defaults)
 	if err != nil {
 		return err
 	}
 
-	var outputDir string
-	switch values.Runtime.Type {
-	case runtimePlugin:
-		outputDir = filepath.Join(s.outputDir, "plugins")
-	default:
-		outputDir = filepath.Join(s.outputDir, "actions")
+	newGen := func(values *templateValues) *generator {
+		return newGenerator(actionsDir(s.outputDir, values.Runtime.Type), tmplManager, generatorOptions{
+			dryRun:      s.dryRun,
+			conflict:    s.conflict,
+			saveAnswers: s.prompt.interactive || s.replay != "",
+		})
+	}
+
+	// Show what will be generated, the dry run prints it anyway.
+	if s.prompt.interactive && !s.dryRun {
+		err = metadata.confirmSummary(values, newGen)
+		if errors.Is(err, errSummaryCancelled) {
+			launchr.Term().Info().Printfln("Generation cancelled, nothing was written")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	gen := newGen(values)
+	err = gen.generate(values)
+	if err != nil {
+		return err
+	}
+
+	if values.Runtime.Type == runtimePlugin {
+		return integratePlugin(gen.dirManager.getActionDir(values.ID), s.register, s.dryRun)
 	}
 
-	gen := newGenerator(outputDir)
-	return gen.generate(values)
+	return nil
 }
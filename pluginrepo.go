@@ -0,0 +1,70 @@
+package scaffold
+
+import (
+	"strings"
+	"unicode"
+)
+
+// templatesPluginRepoDir holds the standalone plugin project skeleton, e.g. plugin.go and go.mod
+const templatesPluginRepoDir = "plugin-repo"
+
+// pluginRepoValues are values of the standalone plugin project templates
+type pluginRepoValues struct {
+	*appValues
+	Package  string // Go package name derived from the plugin name
+	Title    string // Title of the plugin action
+	Weight   int    // Plugin weight in [launchr.PluginInfo]
+	Services []*launchrService
+}
+
+// newPluginRepoValues validates the plugin name, module path and services
+func newPluginRepoValues(name, modulePath, launchrVersion string, weight int, services []string) (*pluginRepoValues, error) {
+	app, err := newAppValues(name, modulePath, launchrVersion)
+	if err != nil {
+		return nil, err
+	}
+	// The plugin is developed with launchr built from cmd/launchr.
+	app.Binary = "launchr"
+
+	s, err := findLaunchrServices(services)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pluginRepoValues{
+		appValues: app,
+		Package:   goPackage(name),
+		Title:     nameToTitle(name),
+		Weight:    weight,
+		Services:  s,
+	}, nil
+}
+
+// nameToTitle makes a title of a name, e.g. "my-plugin" becomes "My plugin"
+func nameToTitle(name string) string {
+	title := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' {
+			return ' '
+		}
+		return r
+	}, name)
+
+	runes := []rune(title)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// renderPluginRepo renders the standalone plugin project in memory
+func (t *templateManager) renderPluginRepo(values *pluginRepoValues) ([]*renderedFile, error) {
+	return t.renderProject(templatesPluginRepoDir, values.Name, values)
+}
+
+// generatePluginRepo writes the standalone plugin project to the directory
+func (g *generator) generatePluginRepo(dir string, values *pluginRepoValues) error {
+	files, err := g.tmplManager.renderPluginRepo(values)
+	if err != nil {
+		return err
+	}
+
+	return g.generateProject("Plugin", values.Name, dir, files)
+}
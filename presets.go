@@ -0,0 +1,239 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/launchrctl/launchr"
+	"github.com/launchrctl/launchr/pkg/action"
+	"gopkg.in/yaml.v3"
+)
+
+// Preset describes a set of templates rendered into the action directory.
+type Preset struct {
+	// Name is a unique preset name used as the --preset value.
+	Name string
+	// Description is shown in the interactive form.
+	Description string
+	// Runtime is the action runtime type the preset is made for.
+	Runtime action.DefRuntimeType
+	// Version is the preset version shown in templates listing.
+	Version string
+	// Command is the default container command, used by the container runtime.
+	Command []string
+	// Templates contains *.tmpl files rendered into the action directory, subdirectories are preserved.
+	// An optional scaffold.yaml manifest declares custom variables.
+	Templates fs.FS
+
+	origin   string // Where the preset comes from, "embedded" or a plugin
+	filesDir string // Directory in templates sources, relative to the files root
+}
+
+// PresetProvider is implemented by a [launchr.Plugin] contributing scaffold presets.
+// Presets are discovered when the application is initialized.
+type PresetProvider interface {
+	launchr.Plugin
+	ScaffoldPresets() []*Preset
+}
+
+// presetRegistry holds built-in and contributed presets in registration order
+type presetRegistry struct {
+	presets []*Preset
+}
+
+// newPresetRegistry creates a registry with the embedded presets
+func newPresetRegistry() *presetRegistry {
+	r := &presetRegistry{}
+	builtin := []*Preset{
+		{
+			Name:        "go",
+			Description: "Golang",
+			Runtime:     runtimeContainer,
+			Command:     []string{"/app/main"},
+		},
+		{
+			Name:        "py",
+			Description: "Python",
+			Runtime:     runtimeContainer,
+			Command:     []string{"python3", "-B", "/action/main.py"},
+		},
+		{
+			Name:        "sh",
+			Description: "Shell",
+			Runtime:     runtimeContainer,
+			Command:     []string{"sh", "/action/main.sh"},
+		},
+	}
+	for _, p := range builtin {
+		p.Version = templatesVersion
+		p.origin = "embedded"
+		p.filesDir = path.Join(string(p.Runtime), p.Name)
+		r.presets = append(r.presets, p)
+	}
+
+	return r
+}
+
+// register adds a preset contributed by a plugin
+func (r *presetRegistry) register(p *Preset, origin string) error {
+	if p == nil {
+		return errors.New("preset can't be nil")
+	}
+
+	err := isValidName("preset name", p.Name)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(specRuntimes, p.Runtime) {
+		return fmt.Errorf("preset '%s' has unknown runtime '%s'", p.Name, p.Runtime)
+	}
+	if p.Templates == nil {
+		return fmt.Errorf("preset '%s' has no templates", p.Name)
+	}
+	if existing := r.get(p.Name); existing != nil {
+		return fmt.Errorf("preset '%s' from %s is already provided by %s", p.Name, origin, existing.origin)
+	}
+
+	p.origin = origin
+	// Contributed presets can still be overridden in templates directories.
+	p.filesDir = path.Join("presets", p.Name)
+	r.presets = append(r.presets, p)
+	return nil
+}
+
+// discover registers presets of plugins implementing [PresetProvider]
+func (r *presetRegistry) discover(plugins launchr.PluginsMap) {
+	var providers []PresetProvider
+	for _, pl := range plugins {
+		if pp, ok := pl.(PresetProvider); ok {
+			providers = append(providers, pp)
+		}
+	}
+
+	// Plugins map has no order, keep registration stable.
+	slices.SortFunc(providers, func(a, b PresetProvider) int {
+		return strings.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b))
+	})
+
+	for _, pp := range providers {
+		origin := fmt.Sprintf("%T", pp)
+		for _, p := range pp.ScaffoldPresets() {
+			if err := r.register(p, origin); err != nil {
+				launchr.Term().Warning().Printfln("Skipping scaffold preset of %s: %v", origin, err)
+			}
+		}
+	}
+}
+
+// get returns a preset by name
+func (r *presetRegistry) get(name string) *Preset {
+	for _, p := range r.presets {
+		if p.Name == name {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// forRuntime returns presets made for the runtime type
+func (r *presetRegistry) forRuntime(rt action.DefRuntimeType) []*Preset {
+	var res []*Preset
+	for _, p := range r.presets {
+		if p.Runtime == rt {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+// names returns names of all presets
+func (r *presetRegistry) names() []string {
+	res := make([]string, 0, len(r.presets))
+	for _, p := range r.presets {
+		res = append(res, p.Name)
+	}
+
+	return res
+}
+
+// resolve returns the preset used for the values, nil means the default files of the runtime.
+// The container runtime always requires a preset, other runtimes use one only if it matches the runtime.
+func (r *presetRegistry) resolve(values *templateValues) (*Preset, error) {
+	p := r.get(values.ContainerPreset)
+	if p != nil && p.Runtime == values.Runtime.Type {
+		return p, nil
+	}
+
+	if values.Runtime.Type == runtimeContainer {
+		if p == nil {
+			return nil, fmt.Errorf("unknown preset '%s', expected one of %v", values.ContainerPreset, r.names())
+		}
+		return nil, fmt.Errorf("preset '%s' is made for the %s runtime", p.Name, p.Runtime)
+	}
+
+	return nil, nil
+}
+
+// withPresetsEnum sets registered preset names as allowed values of the preset option in the action definition
+func withPresetsEnum(def []byte, names []string) ([]byte, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(def, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	options := yamlMapValue(yamlMapValue(doc.Content[0], "action"), "options")
+	if options == nil {
+		return nil, errors.New("action definition has no options")
+	}
+
+	for _, opt := range options.Content {
+		if name := yamlMapValue(opt, "name"); name == nil || name.Value != "preset" {
+			continue
+		}
+
+		enum := yamlMapValue(opt, "enum")
+		if enum == nil {
+			return nil, errors.New("preset option has no enum")
+		}
+		enum.Kind = yaml.SequenceNode
+		enum.Style = yaml.FlowStyle
+		enum.Content = nil
+		for _, n := range names {
+			enum.Content = append(enum.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: n, Style: yaml.DoubleQuotedStyle})
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	_ = enc.Close()
+
+	return buf.Bytes(), nil
+}
+
+// yamlMapValue returns the value of a key in a mapping node
+func yamlMapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
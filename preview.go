@@ -0,0 +1,113 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/launchrctl/launchr"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// preview prints the rendered files without touching the filesystem.
+// Files that already exist on disk are shown as a unified diff.
+func (g *generator) preview(dir string, files []*renderedFile, nonEmpty bool) error {
+	launchr.Term().Info().Printfln("Dry run: files would be generated in %s", dir)
+	if nonEmpty && g.opts.conflict == conflictFail {
+		launchr.Term().Warning().Printfln("Directory %s is not empty, generation will fail without --force or --merge", dir)
+	}
+
+	launchr.Term().Print(formatFileTree(dir, renderedPaths(files)))
+
+	for _, f := range files {
+		outputPath := filepath.Join(dir, f.path)
+		existing, err := os.ReadFile(filepath.Clean(outputPath))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			launchr.Term().Info().Printfln("%s (new file)", outputPath)
+			launchr.Term().Println(string(f.content))
+		case err != nil:
+			return fmt.Errorf("failed to read %s: %w", outputPath, err)
+		case g.opts.conflict == conflictMerge:
+			launchr.Term().Info().Printfln("%s (exists, skipped)", outputPath)
+		case bytes.Equal(existing, f.content):
+			launchr.Term().Info().Printfln("%s (unchanged)", outputPath)
+		default:
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(existing)),
+				B:        difflib.SplitLines(string(f.content)),
+				FromFile: outputPath,
+				ToFile:   outputPath + " (scaffold)",
+				Context:  3,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to diff %s: %w", outputPath, err)
+			}
+			launchr.Term().Info().Printfln("%s (modified)", outputPath)
+			launchr.Term().Println(diff)
+		}
+	}
+
+	return nil
+}
+
+// renderedPaths returns paths of rendered files
+func renderedPaths(files []*renderedFile) []string {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+
+	return paths
+}
+
+// treeNode is a directory entry of a printed file tree
+type treeNode struct {
+	children map[string]*treeNode
+}
+
+// formatFileTree renders a list of relative file paths as a tree rooted in root
+func formatFileTree(root string, paths []string) string {
+	tree := &treeNode{children: make(map[string]*treeNode)}
+	for _, p := range paths {
+		node := tree
+		for _, part := range strings.Split(filepath.ToSlash(p), "/") {
+			child, ok := node.children[part]
+			if !ok {
+				child = &treeNode{children: make(map[string]*treeNode)}
+				node.children[part] = child
+			}
+			node = child
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(root + "/\n")
+	tree.write(&b, "")
+	return b.String()
+}
+
+func (n *treeNode) write(b *strings.Builder, indent string) {
+	names := slices.Sorted(maps.Keys(n.children))
+	for i, name := range names {
+		child := n.children[name]
+		branch, next := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		suffix := ""
+		if len(child.children) > 0 {
+			suffix = "/"
+		}
+
+		b.WriteString(indent + branch + name + suffix + "\n")
+		child.write(b, indent+next)
+	}
+}
@@ -0,0 +1,124 @@
+package scaffold
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/huh"
+	"github.com/launchrctl/launchr"
+)
+
+// promptOptions defines how questions are asked in the interactive mode
+type promptOptions struct {
+	interactive bool
+	accessible  bool // Ask line by line over plain input and output instead of terminal forms
+	terminal    bool // Input is a terminal, e.g. an editor can be opened
+	in          io.Reader
+	out         io.Writer
+}
+
+// newPromptOptions creates prompt options for the action streams.
+// Accessible mode is selected automatically when the input is not a terminal,
+// so piped answers and tools without a TTY still get the questions.
+func newPromptOptions(interactive, accessible bool, streams launchr.Streams) promptOptions {
+	opts := promptOptions{
+		interactive: interactive || accessible,
+		accessible:  accessible,
+		in:          os.Stdin,
+		out:         os.Stdout,
+	}
+
+	if streams != nil {
+		opts.terminal = streams.In().IsTerminal()
+		opts.in = streams.In()
+		opts.out = streams.Out()
+	}
+
+	if opts.interactive && !opts.terminal {
+		opts.accessible = true
+	}
+	if opts.accessible {
+		opts.in = &lineReader{r: bufio.NewReader(opts.in)}
+	}
+
+	return opts
+}
+
+// hideGroup hides a form group when the condition holds
+func (m *metadataCollector) hideGroup(g *huh.Group, hide func() bool) *huh.Group {
+	if m.hidden == nil {
+		m.hidden = make(map[*huh.Group]func() bool)
+	}
+	m.hidden[g] = hide
+
+	return g.WithHideFunc(hide)
+}
+
+// runForm asks questions of form groups the way the collector is configured
+func (m *metadataCollector) runForm(groups ...*huh.Group) error {
+	defer func() {
+		for _, g := range groups {
+			delete(m.hidden, g)
+		}
+	}()
+
+	if !m.prompt.accessible {
+		return huh.NewForm(groups...).Run()
+	}
+
+	// Accessible forms ask every field regardless of hidden groups, run groups one by one to skip them.
+	for _, g := range groups {
+		if hide := m.hidden[g]; hide != nil && hide() {
+			continue
+		}
+
+		err := huh.NewForm(g).
+			WithAccessible(true).
+			WithInput(m.prompt.in).
+			WithOutput(m.prompt.out).
+			Run()
+		if err != nil {
+			return err
+		}
+
+		// Prompts fall back to defaults at the end of input, don't let questions loop forever.
+		if lr, ok := m.prompt.in.(*lineReader); ok && lr.eof {
+			return fmt.Errorf("input ended before all questions were answered: %w", io.ErrUnexpectedEOF)
+		}
+	}
+
+	return nil
+}
+
+// lineReader returns at most one line per read. Accessible prompts create
+// a buffered scanner for every question, reading line by line keeps answers
+// of the next questions in the input.
+type lineReader struct {
+	r       *bufio.Reader
+	pending []byte
+	// unterminated is set when the last line has no trailing newline, the end of input only terminates it.
+	unterminated bool
+	eof          bool // A question found no input left
+}
+
+func (l *lineReader) Read(p []byte) (int, error) {
+	if len(l.pending) == 0 {
+		line, err := l.r.ReadBytes('\n')
+		if len(line) == 0 {
+			if l.unterminated {
+				l.unterminated = false
+			} else {
+				l.eof = true
+			}
+			return 0, err
+		}
+		l.pending = line
+		l.unterminated = line[len(line)-1] != '\n'
+	}
+
+	n := copy(p, l.pending)
+	l.pending = l.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,92 @@
+package scaffold
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/huh"
+)
+
+func TestLineReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		questions int
+		want      []string // Answers read by the questions
+		wantEOF   bool
+	}{
+		{name: "trailing newline", input: "a\nb\n", questions: 2, want: []string{"a", "b"}},
+		{name: "no trailing newline", input: "a\nb", questions: 2, want: []string{"a", "b"}},
+		{name: "empty answer", input: "a\n\nc\n", questions: 3, want: []string{"a", "", "c"}},
+		{name: "answers left", input: "a\nb\nc\n", questions: 2, want: []string{"a", "b"}},
+		{name: "unanswered question", input: "a\n", questions: 2, want: []string{"a", ""}, wantEOF: true},
+		{name: "unanswered after unterminated line", input: "a", questions: 2, want: []string{"a", ""}, wantEOF: true},
+		{name: "empty input", input: "", questions: 1, want: []string{""}, wantEOF: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := &lineReader{r: bufio.NewReader(strings.NewReader(tt.input))}
+
+			// Accessible prompts scan every question with a new scanner.
+			var got []string
+			for i := 0; i < tt.questions; i++ {
+				s := bufio.NewScanner(lr)
+				s.Scan()
+				got = append(got, s.Text())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("answers = %q, want %q", got, tt.want)
+			}
+			if lr.eof != tt.wantEOF {
+				t.Errorf("eof = %v, want %v", lr.eof, tt.wantEOF)
+			}
+		})
+	}
+}
+
+func TestRunFormPipedInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "trailing newline", input: "a\nb\n", want: []string{"a", "b"}},
+		{name: "no trailing newline", input: "a\nb", want: []string{"a", "b"}},
+		{name: "empty last answer", input: "a\n\n", want: []string{"a", ""}},
+		{name: "missing answer", input: "a\n", wantErr: true},
+		{name: "empty input", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &lineReader{r: bufio.NewReader(strings.NewReader(tt.input))}
+			m := &metadataCollector{prompt: promptOptions{interactive: true, accessible: true, in: in, out: io.Discard}}
+
+			var first, second string
+			err := m.runForm(
+				huh.NewGroup(huh.NewInput().Title("First").Value(&first)),
+				huh.NewGroup(huh.NewInput().Title("Second").Value(&second)),
+			)
+			if tt.wantErr {
+				if !errors.Is(err, io.ErrUnexpectedEOF) {
+					t.Errorf("runForm() error = %v, want %v", err, io.ErrUnexpectedEOF)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runForm() error = %v", err)
+			}
+
+			if got := []string{first, second}; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("answers = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
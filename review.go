@@ -0,0 +1,179 @@
+package scaffold
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/huh"
+	"github.com/launchrctl/launchr/pkg/action"
+)
+
+// Parameter kinds shown on the review screen.
+const (
+	paramKindArgument = "argument"
+	paramKindOption   = "option"
+)
+
+// paramRef points at a collected parameter
+type paramRef struct {
+	kind  string
+	index int
+}
+
+// Review screen choices not pointing at a parameter.
+var (
+	reviewDone      = paramRef{index: -1}
+	reviewAddArg    = paramRef{kind: paramKindArgument, index: -1}
+	reviewAddOption = paramRef{kind: paramKindOption, index: -1}
+)
+
+// paramAction is an operation on a parameter picked on the review screen
+type paramAction int
+
+const (
+	paramActionBack paramAction = iota
+	paramActionEdit
+	paramActionDelete
+	paramActionUp
+	paramActionDown
+	paramActionMove
+)
+
+// parametersList returns the list of arguments or options
+func (v *templateValues) parametersList(kind string) *action.ParametersList {
+	if kind == paramKindArgument {
+		return &v.Action.Arguments
+	}
+
+	return &v.Action.Options
+}
+
+// otherKind returns the kind a parameter is moved to
+func otherKind(kind string) string {
+	if kind == paramKindArgument {
+		return paramKindOption
+	}
+
+	return paramKindArgument
+}
+
+// reviewParameters lists collected arguments and options and lets the user
+// edit, delete, reorder or move them between arguments and options
+func (m *metadataCollector) reviewParameters(values *templateValues) error {
+	for {
+		choice := reviewDone
+		err := m.runForm(
+			huh.NewGroup(
+				huh.NewSelect[paramRef]().
+					Title("Review parameters").
+					Description("Arguments are positional, their order matters").
+					Options(reviewOptions(values)...).
+					Value(&choice),
+			),
+		)
+		if err != nil {
+			return fmt.Errorf("form error: %w", err)
+		}
+
+		switch choice {
+		case reviewDone:
+			return nil
+		case reviewAddArg, reviewAddOption:
+			param, err := m.runParameterForm(choice.kind, values, nil)
+			if err != nil {
+				return err
+			}
+			params := values.parametersList(choice.kind)
+			*params = append(*params, param)
+		default:
+			err = m.reviewParameter(values, choice)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reviewOptions creates review screen options for collected parameters
+func reviewOptions(values *templateValues) []huh.Option[paramRef] {
+	var options []huh.Option[paramRef]
+	for _, kind := range []string{paramKindArgument, paramKindOption} {
+		for i, p := range *values.parametersList(kind) {
+			label := fmt.Sprintf("%s %d: %s (%s)", kind, i+1, p.Name, p.Type)
+			if p.Required {
+				label += ", required"
+			}
+			options = append(options, huh.NewOption(label, paramRef{kind: kind, index: i}))
+		}
+	}
+
+	return append(options,
+		huh.NewOption("Add argument", reviewAddArg),
+		huh.NewOption("Add option", reviewAddOption),
+		huh.NewOption("Done", reviewDone),
+	)
+}
+
+// reviewParameter applies an operation picked by the user to a parameter
+func (m *metadataCollector) reviewParameter(values *templateValues, ref paramRef) error {
+	params := values.parametersList(ref.kind)
+	param := (*params)[ref.index]
+
+	actions := []huh.Option[paramAction]{
+		huh.NewOption("Edit", paramActionEdit),
+		huh.NewOption("Delete", paramActionDelete),
+	}
+	if ref.index > 0 {
+		actions = append(actions, huh.NewOption("Move up", paramActionUp))
+	}
+	if ref.index < len(*params)-1 {
+		actions = append(actions, huh.NewOption("Move down", paramActionDown))
+	}
+	actions = append(actions,
+		huh.NewOption(fmt.Sprintf("Make it an %s", otherKind(ref.kind)), paramActionMove),
+		huh.NewOption("Back", paramActionBack),
+	)
+
+	var act paramAction
+	confirmed := true
+	err := m.runForm(
+		huh.NewGroup(
+			huh.NewSelect[paramAction]().
+				Title(fmt.Sprintf("%s '%s'", ref.kind, param.Name)).
+				Options(actions...).
+				Value(&act),
+		),
+		m.hideGroup(huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Delete %s '%s'?", ref.kind, param.Name)).
+				Value(&confirmed),
+		), func() bool { return act != paramActionDelete }),
+	)
+	if err != nil {
+		return fmt.Errorf("form error: %w", err)
+	}
+
+	switch act {
+	case paramActionEdit:
+		edited, err := m.runParameterForm(ref.kind, values, param)
+		if err != nil {
+			return err
+		}
+		(*params)[ref.index] = edited
+	case paramActionDelete:
+		if confirmed {
+			*params = slices.Delete(*params, ref.index, ref.index+1)
+			values.setConstraints(param.Name, nil)
+		}
+	case paramActionUp:
+		(*params)[ref.index-1], (*params)[ref.index] = (*params)[ref.index], (*params)[ref.index-1]
+	case paramActionDown:
+		(*params)[ref.index+1], (*params)[ref.index] = (*params)[ref.index], (*params)[ref.index+1]
+	case paramActionMove:
+		*params = slices.Delete(*params, ref.index, ref.index+1)
+		other := values.parametersList(otherKind(ref.kind))
+		*other = append(*other, param)
+	}
+
+	return nil
+}
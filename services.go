@@ -0,0 +1,55 @@
+package scaffold
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// launchrService is a service a plugin gets from [launchr.App] in OnAppInit
+type launchrService struct {
+	Name  string // Value of the services option
+	Title string // Shown in forms
+	Field string // Field of the plugin struct keeping the service
+	Type  string // Go type of the service
+}
+
+// launchrServices are services commonly used by plugins
+var launchrServices = []*launchrService{
+	{Name: "actions", Title: "Action manager", Field: "manager", Type: "action.Manager"},
+	{Name: "config", Title: "Config", Field: "cfg", Type: "launchr.Config"},
+	{Name: "plugins", Title: "Plugin manager", Field: "pluginManager", Type: "launchr.PluginManager"},
+}
+
+// launchrServiceNames returns names of the known services
+func launchrServiceNames() []string {
+	return serviceNames(launchrServices)
+}
+
+// serviceNames returns names of the services
+func serviceNames(services []*launchrService) []string {
+	names := make([]string, 0, len(services))
+	for _, s := range services {
+		names = append(names, s.Name)
+	}
+
+	return names
+}
+
+// findLaunchrServices returns services by names in the order of [launchrServices]
+func findLaunchrServices(names []string) ([]*launchrService, error) {
+	for _, name := range names {
+		if !slices.Contains(launchrServiceNames(), name) {
+			return nil, fmt.Errorf("unknown service '%s', expected one of %s", name, strings.Join(launchrServiceNames(), ", "))
+		}
+	}
+
+	var res []*launchrService
+	for _, s := range launchrServices {
+		if slices.Contains(names, s.Name) {
+			res = append(res, s)
+		}
+	}
+
+	return res, nil
+}
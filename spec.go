@@ -0,0 +1,385 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// actionSpec describes an action to scaffold non-interactively.
+// It is read from a YAML or JSON file and mirrors [templateValues].
+type actionSpec struct {
+	ID               string       `yaml:"id,omitempty"`
+	Title            string       `yaml:"title,omitempty"`
+	Description      string       `yaml:"description,omitempty"`
+	Aliases          []string     `yaml:"aliases,omitempty"`
+	Runtime          string       `yaml:"runtime,omitempty"`
+	Preset           string       `yaml:"preset,omitempty"`
+	WorkingDirectory string       `yaml:"working_directory,omitempty"`
+	Image            string       `yaml:"image,omitempty"`
+	Env              []string     `yaml:"env,omitempty"`
+	ExtraHosts       []string     `yaml:"extra_hosts,omitempty"`
+	Services         []string     `yaml:"services,omitempty"`
+	Arguments        []*paramSpec `yaml:"arguments,omitempty"`
+	Options          []*paramSpec `yaml:"options,omitempty"`
+	// Vars holds values of custom variables declared in the preset manifest.
+	Vars map[string]any `yaml:"vars,omitempty"`
+
+	// Answers file metadata, ignored when the spec is applied.
+	TemplateVersion string    `yaml:"template_version,omitempty"`
+	GeneratedAt     time.Time `yaml:"generated_at,omitempty"`
+}
+
+// paramSpec describes an action argument or option in [actionSpec]
+type paramSpec struct {
+	Name        string          `yaml:"name"`
+	Title       string          `yaml:"title,omitempty"`
+	Description string          `yaml:"description,omitempty"`
+	Type        jsonschema.Type `yaml:"type,omitempty"`
+	Required    bool            `yaml:"required,omitempty"`
+	Default     any             `yaml:"default,omitempty"`
+	Enum        []any           `yaml:"enum,omitempty"`
+	Items       *itemsSpec      `yaml:"items,omitempty"`
+
+	paramConstraints `yaml:",inline"`
+}
+
+// itemsSpec describes array items of [paramSpec]
+type itemsSpec struct {
+	Type jsonschema.Type `yaml:"type"`
+}
+
+// answersFileName is a file in the action directory keeping the values it was generated with
+const answersFileName = ".scaffold-answers.yaml"
+
+var (
+	specRuntimes = []action.DefRuntimeType{runtimePlugin, runtimeContainer, runtimeShell}
+	specTypes    = []jsonschema.Type{jsonschema.String, jsonschema.Number, jsonschema.Integer, jsonschema.Boolean, jsonschema.Array}
+)
+
+// loadActionSpec reads an action spec from a YAML or JSON file
+func loadActionSpec(path string) (*actionSpec, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+
+	spec := &actionSpec{}
+	// JSON is a subset of YAML, so a single decoder handles both formats.
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(spec)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse spec file %s: %w", path, err)
+	}
+
+	return spec, nil
+}
+
+// newActionSpec creates a spec from collected values, the reverse of [actionSpec.apply]
+func newActionSpec(values *templateValues) *actionSpec {
+	s := &actionSpec{
+		ID:               values.ID,
+		Title:            values.Action.Title,
+		Description:      values.Action.Description,
+		Aliases:          values.Action.Aliases,
+		Runtime:          string(values.Runtime.Type),
+		WorkingDirectory: values.WD,
+		Arguments:        newParamSpecs(values.Action.Arguments, values.Constraints),
+		Options:          newParamSpecs(values.Action.Options, values.Constraints),
+		Vars:             values.Vars,
+	}
+
+	if values.ContainerPreset != "" {
+		s.Preset = values.ContainerPreset
+	}
+
+	switch values.Runtime.Type {
+	case runtimeContainer:
+		// A derived image depends on the ID and the project config, it's derived again on replay.
+		if !values.derived.image {
+			s.Image = values.Runtime.Container.Image
+		}
+		s.Env = values.Runtime.Container.Env
+		s.ExtraHosts = values.Runtime.Container.ExtraHosts
+	case runtimeShell:
+		s.Env = values.Runtime.Shell.Env
+	case runtimePlugin:
+		s.Services = serviceNames(values.Services)
+	}
+
+	return s
+}
+
+func newParamSpecs(params action.ParametersList, constraints map[string]*paramConstraints) []*paramSpec {
+	specs := make([]*paramSpec, 0, len(params))
+	for _, p := range params {
+		ps := &paramSpec{
+			Name:        p.Name,
+			Title:       p.Title,
+			Description: p.Description,
+			Type:        p.Type,
+			Required:    p.Required,
+			Default:     p.Default,
+			Enum:        p.Enum,
+		}
+		if p.Items != nil {
+			ps.Items = &itemsSpec{Type: p.Items.Type}
+		}
+		if c := constraints[p.Name]; c != nil {
+			ps.paramConstraints = *c
+		}
+		specs = append(specs, ps)
+	}
+
+	return specs
+}
+
+// renderAnswers renders the answers file stored in the action directory
+func renderAnswers(values *templateValues) (*renderedFile, error) {
+	spec := newActionSpec(values)
+	spec.TemplateVersion = templatesVersion
+	spec.GeneratedAt = time.Now().UTC().Truncate(time.Second)
+
+	var buf bytes.Buffer
+	buf.WriteString("# Answers collected by scaffold, regenerate the action with:\n")
+	buf.WriteString("#   launchr scaffold --replay <path to this file> --force\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err := enc.Encode(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", answersFileName, err)
+	}
+	_ = enc.Close()
+
+	return &renderedFile{path: answersFileName, content: buf.Bytes()}, nil
+}
+
+// apply overrides values with the fields set in the spec
+func (s *actionSpec) apply(values *templateValues) error {
+	if s.ID != "" {
+		values.ID = s.ID
+	}
+	if s.Title != "" {
+		values.Action.Title = s.Title
+	}
+	if s.Description != "" {
+		values.Action.Description = s.Description
+	}
+	for _, alias := range s.Aliases {
+		alias = strings.TrimSpace(alias)
+		if alias != "" && !slices.Contains(values.Action.Aliases, alias) {
+			values.Action.Aliases = append(values.Action.Aliases, alias)
+		}
+	}
+
+	if s.Runtime != "" {
+		rt := action.DefRuntimeType(s.Runtime)
+		if !slices.Contains(specRuntimes, rt) {
+			return fmt.Errorf("unknown runtime '%s', expected one of %v", s.Runtime, specRuntimes)
+		}
+		values.Runtime.Type = rt
+	}
+	// Presets are validated against the registry when values are collected.
+	if s.Preset != "" {
+		values.ContainerPreset = s.Preset
+	}
+	if s.WorkingDirectory != "" {
+		values.WD = s.WorkingDirectory
+	}
+
+	for _, env := range s.Env {
+		if !strings.Contains(env, "=") {
+			return fmt.Errorf("invalid env '%s', expected KEY=VALUE", env)
+		}
+	}
+	if s.Image != "" {
+		values.Runtime.Container.Image = s.Image
+	}
+	// Variables and hosts set before, e.g. by a replayed answers file, are overridden by the name.
+	values.Runtime.Container.Env = mergeByKey(values.Runtime.Container.Env, s.Env, "=")
+	values.Runtime.Shell.Env = mergeByKey(values.Runtime.Shell.Env, s.Env, "=")
+	values.Runtime.Container.ExtraHosts = mergeByKey(values.Runtime.Container.ExtraHosts, s.ExtraHosts, ":")
+
+	if len(s.Services) > 0 {
+		services, err := findLaunchrServices(append(serviceNames(values.Services), s.Services...))
+		if err != nil {
+			return err
+		}
+		values.Services = services
+	}
+
+	if len(s.Vars) > 0 && values.Vars == nil {
+		values.Vars = make(map[string]any, len(s.Vars))
+	}
+	for name, v := range s.Vars {
+		values.Vars[name] = v
+	}
+
+	// Names are unique within the spec, arguments and options share them.
+	applied := make(map[string]bool, len(s.Arguments)+len(s.Options))
+	err := applyParamSpecs("argument", s.Arguments, values, &values.Action.Arguments, applied)
+	if err != nil {
+		return err
+	}
+
+	return applyParamSpecs("option", s.Options, values, &values.Action.Options, applied)
+}
+
+// mergeByKey adds "key<sep>value" items to the list, an item with the same key is replaced
+func mergeByKey[S ~[]string](list S, items []string, sep string) S {
+	for _, item := range items {
+		key, _, _ := strings.Cut(item, sep)
+		i := slices.IndexFunc(list, func(existing string) bool {
+			k, _, _ := strings.Cut(existing, sep)
+			return k == key
+		})
+		if i >= 0 {
+			list[i] = item
+			continue
+		}
+		list = append(list, item)
+	}
+
+	return list
+}
+
+// parseParamFlag parses a compact parameter definition passed as a flag value.
+// The syntax is name[:type][:required][=default], e.g. "name:string:required" or "count:integer=3".
+// Array items type is set as "array/integer", array default items are separated by "|".
+func parseParamFlag(v string) (*paramSpec, error) {
+	def, defaultStr, hasDefault := strings.Cut(v, "=")
+	parts := strings.Split(def, ":")
+	ps := &paramSpec{Name: strings.TrimSpace(parts[0])}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "required":
+			ps.Required = true
+		case ps.Type == "":
+			typ, itemsType, isArray := strings.Cut(part, "/")
+			ps.Type = jsonschema.Type(typ)
+			if isArray {
+				if ps.Type != jsonschema.Array {
+					return nil, fmt.Errorf("invalid parameter %q: items type is allowed only for arrays", v)
+				}
+				ps.Items = &itemsSpec{Type: jsonschema.Type(itemsType)}
+			}
+		default:
+			return nil, fmt.Errorf("invalid parameter %q: unexpected %q, expected name[:type][:required][=default]", v, part)
+		}
+	}
+
+	if hasDefault {
+		if ps.Type == jsonschema.Array {
+			defaultStr = strings.ReplaceAll(defaultStr, "|", ",")
+		}
+		ps.Default = defaultStr
+	}
+
+	return ps, nil
+}
+
+// applyParamSpecs validates parameter specs and adds them to the parameters list.
+// A parameter with the same name set before, e.g. by a replayed answers file, is replaced.
+func applyParamSpecs(kind string, specs []*paramSpec, values *templateValues, params *action.ParametersList, applied map[string]bool) error {
+	for _, ps := range specs {
+		err := isValidName(kind, ps.Name)
+		if err != nil {
+			return err
+		}
+
+		if ps.Type != "" && !slices.Contains(specTypes, ps.Type) {
+			return fmt.Errorf("%s '%s' has unsupported type '%s'", kind, ps.Name, ps.Type)
+		}
+
+		param := &action.DefParameter{
+			Name:        ps.Name,
+			Title:       ps.Title,
+			Description: ps.Description,
+			Type:        ps.Type,
+			Required:    ps.Required,
+			Default:     ps.Default,
+			Enum:        slices.Clone(ps.Enum),
+		}
+		if ps.Items != nil {
+			if ps.Items.Type == jsonschema.Array || !slices.Contains(specTypes, ps.Items.Type) {
+				return fmt.Errorf("%s '%s' has unsupported items type '%s'", kind, ps.Name, ps.Items.Type)
+			}
+			param.Items = &action.DefArrayItems{Type: ps.Items.Type}
+		}
+
+		err = normalizeParameter(param)
+		if err != nil {
+			return err
+		}
+
+		c := ps.paramConstraints
+		err = normalizeConstraints(param, &c)
+		if err != nil {
+			return err
+		}
+		err = fillDefault(param, &c)
+		if err != nil {
+			return err
+		}
+
+		if applied[param.Name] {
+			return fmt.Errorf("parameter with name '%s' already exists", param.Name)
+		}
+		applied[param.Name] = true
+
+		isNamed := func(p *action.DefParameter) bool { return p.Name == param.Name }
+		if i := slices.IndexFunc(*params, isNamed); i >= 0 {
+			(*params)[i] = param
+		} else {
+			// The parameter may be declared as the other kind.
+			values.Action.Arguments = slices.DeleteFunc(values.Action.Arguments, isNamed)
+			values.Action.Options = slices.DeleteFunc(values.Action.Options, isNamed)
+			*params = append(*params, param)
+		}
+		values.setConstraints(param.Name, &c)
+	}
+
+	return nil
+}
+
+// parseConstraintFlag parses a parameter constraint passed as a flag value.
+// The syntax is param:key=value, e.g. "count:minimum=1" or "mode:enum=fast|slow".
+func parseConstraintFlag(v string, params ...[]*paramSpec) error {
+	name, constraint, ok := strings.Cut(v, ":")
+	key, value, hasValue := strings.Cut(constraint, "=")
+	if !ok || !hasValue {
+		return fmt.Errorf("invalid constraint %q, expected param:key=value", v)
+	}
+
+	for _, list := range params {
+		for _, ps := range list {
+			if ps.Name != strings.TrimSpace(name) {
+				continue
+			}
+			if key == "enum" {
+				ps.Enum = parseEnum(value)
+				return nil
+			}
+
+			err := ps.set(key, value)
+			if err != nil {
+				return fmt.Errorf("invalid constraint %q: %w", v, err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid constraint %q: parameter '%s' is not passed with --arg or --opt", v, name)
+}
@@ -0,0 +1,252 @@
+package scaffold
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseParamFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		want    *paramSpec
+		wantErr bool
+	}{
+		{name: "name only", flag: "name", want: &paramSpec{Name: "name"}},
+		{name: "type", flag: "name:string", want: &paramSpec{Name: "name", Type: jsonschema.String}},
+		{name: "required", flag: "name:string:required", want: &paramSpec{Name: "name", Type: jsonschema.String, Required: true}},
+		{name: "required without type", flag: "name:required", want: &paramSpec{Name: "name", Required: true}},
+		{name: "default", flag: "count:integer=3", want: &paramSpec{Name: "count", Type: jsonschema.Integer, Default: "3"}},
+		{name: "default with separators", flag: "url:string=http://a:b=c", want: &paramSpec{Name: "url", Type: jsonschema.String, Default: "http://a:b=c"}},
+		{name: "empty default", flag: "name:string=", want: &paramSpec{Name: "name", Type: jsonschema.String, Default: ""}},
+		{
+			name: "array items",
+			flag: "ids:array/integer=1|2",
+			want: &paramSpec{Name: "ids", Type: jsonschema.Array, Items: &itemsSpec{Type: jsonschema.Integer}, Default: "1,2"},
+		},
+		{name: "spaces", flag: " name : string : required ", want: &paramSpec{Name: "name", Type: jsonschema.String, Required: true}},
+		{name: "items of a scalar", flag: "ids:string/integer", wantErr: true},
+		{name: "unexpected part", flag: "name:string:integer", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseParamFlag(tt.flag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseParamFlag(%q) error = %v, wantErr %v", tt.flag, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseParamFlag(%q) = %+v, want %+v", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActionSpecApplyOverrides(t *testing.T) {
+	// replayed is applied first as an answers file, flags are applied on top of it.
+	replayed := &actionSpec{
+		Env:        []string{"FOO=old", "BAR=keep"},
+		ExtraHosts: []string{"db:127.0.0.1"},
+		Arguments:  []*paramSpec{{Name: "name", Type: jsonschema.String}},
+		Options: []*paramSpec{
+			{Name: "count", Type: jsonschema.Integer, Default: 1, paramConstraints: paramConstraints{Minimum: new(float64)}},
+			{Name: "mode", Type: jsonschema.String},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		flags    *actionSpec
+		wantEnv  []string
+		wantHost []string
+		wantArgs []string
+		wantOpts []string
+		check    func(t *testing.T, values *templateValues)
+		wantErr  bool
+	}{
+		{
+			name:     "env and hosts override by key",
+			flags:    &actionSpec{Env: []string{"FOO=new", "BAZ=added"}, ExtraHosts: []string{"db:10.0.0.1", "cache:10.0.0.2"}},
+			wantEnv:  []string{"FOO=new", "BAR=keep", "BAZ=added"},
+			wantHost: []string{"db:10.0.0.1", "cache:10.0.0.2"},
+			wantArgs: []string{"name"},
+			wantOpts: []string{"count", "mode"},
+		},
+		{
+			name:     "parameter is replaced in place",
+			flags:    &actionSpec{Options: []*paramSpec{{Name: "count", Type: jsonschema.Integer, Default: "3"}}},
+			wantEnv:  []string{"FOO=old", "BAR=keep"},
+			wantHost: []string{"db:127.0.0.1"},
+			wantArgs: []string{"name"},
+			wantOpts: []string{"count", "mode"},
+			check: func(t *testing.T, values *templateValues) {
+				if got := values.Action.Options[0].Default; got != 3 {
+					t.Errorf("count default = %v, want 3", got)
+				}
+				if _, ok := values.Constraints["count"]; ok {
+					t.Errorf("constraints of the replaced parameter are kept")
+				}
+			},
+		},
+		{
+			name:     "parameter changes its kind",
+			flags:    &actionSpec{Arguments: []*paramSpec{{Name: "mode", Type: jsonschema.String, Required: true}}},
+			wantEnv:  []string{"FOO=old", "BAR=keep"},
+			wantHost: []string{"db:127.0.0.1"},
+			wantArgs: []string{"name", "mode"},
+			wantOpts: []string{"count"},
+		},
+		{
+			name:    "duplicate flags",
+			flags:   &actionSpec{Arguments: []*paramSpec{{Name: "new"}}, Options: []*paramSpec{{Name: "new"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := (&scaffoldAction{runtime: runtimeContainer, id: "test", title: "Test"}).getDefaultValues()
+			if err := replayed.apply(values); err != nil {
+				t.Fatal(err)
+			}
+
+			err := tt.flags.apply(values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := []string(values.Runtime.Container.Env); !reflect.DeepEqual(got, tt.wantEnv) {
+				t.Errorf("container env = %v, want %v", got, tt.wantEnv)
+			}
+			if got := []string(values.Runtime.Shell.Env); !reflect.DeepEqual(got, tt.wantEnv) {
+				t.Errorf("shell env = %v, want %v", got, tt.wantEnv)
+			}
+			if got := []string(values.Runtime.Container.ExtraHosts); !reflect.DeepEqual(got, tt.wantHost) {
+				t.Errorf("extra hosts = %v, want %v", got, tt.wantHost)
+			}
+			if got := paramNames(values.Action.Arguments); !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("arguments = %v, want %v", got, tt.wantArgs)
+			}
+			if got := paramNames(values.Action.Options); !reflect.DeepEqual(got, tt.wantOpts) {
+				t.Errorf("options = %v, want %v", got, tt.wantOpts)
+			}
+			if tt.check != nil {
+				tt.check(t, values)
+			}
+		})
+	}
+}
+
+// paramNames returns names of the parameters
+func paramNames(params action.ParametersList) []string {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+
+	return names
+}
+
+func TestParseConstraintFlag(t *testing.T) {
+	minimum, minLength := 1.0, 2
+	tests := []struct {
+		name    string
+		flag    string
+		want    *paramSpec // Constraints of the "count" or "mode" parameter
+		wantErr bool
+	}{
+		{name: "minimum", flag: "count:minimum=1", want: &paramSpec{Name: "count", paramConstraints: paramConstraints{Minimum: &minimum}}},
+		{name: "min length", flag: "mode:minLength=2", want: &paramSpec{Name: "mode", paramConstraints: paramConstraints{MinLength: &minLength}}},
+		{name: "pattern with separators", flag: "mode:pattern=^a:b=c$", want: &paramSpec{Name: "mode", paramConstraints: paramConstraints{Pattern: "^a:b=c$"}}},
+		{name: "enum", flag: "mode:enum=fast|slow", want: &paramSpec{Name: "mode", Enum: []any{"fast", "slow"}}},
+		{name: "format", flag: "mode:format=email", want: &paramSpec{Name: "mode", paramConstraints: paramConstraints{Format: "email"}}},
+		{name: "unknown parameter", flag: "other:minimum=1", wantErr: true},
+		{name: "unknown constraint", flag: "count:multipleOf=2", wantErr: true},
+		{name: "invalid value", flag: "count:minimum=one", wantErr: true},
+		{name: "missing value", flag: "count:minimum", wantErr: true},
+		{name: "missing parameter", flag: "minimum=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []*paramSpec{{Name: "count"}}
+			opts := []*paramSpec{{Name: "mode"}}
+			err := parseConstraintFlag(tt.flag, args, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConstraintFlag(%q) error = %v, wantErr %v", tt.flag, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := args[0]
+			if tt.want.Name == "mode" {
+				got = opts[0]
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseConstraintFlag(%q) = %+v, want %+v", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActionSpecDefaults(t *testing.T) {
+	minimum := 1.0
+	tests := []struct {
+		name    string
+		option  *paramSpec
+		want    any  // Emitted default
+		absent  bool // Default must not be emitted
+		wantErr bool
+	}{
+		{name: "enum without default", option: &paramSpec{Name: "mode", Type: jsonschema.String, Enum: []any{"fast", "slow"}}, absent: true},
+		{name: "minimum without default", option: &paramSpec{Name: "count", Type: jsonschema.Integer, paramConstraints: paramConstraints{Minimum: &minimum}}, absent: true},
+		{name: "unconstrained string", option: &paramSpec{Name: "mode", Type: jsonschema.String}, want: ""},
+		{name: "unconstrained integer", option: &paramSpec{Name: "count", Type: jsonschema.Integer}, want: 0},
+		{name: "empty default out of enum", option: &paramSpec{Name: "mode", Type: jsonschema.String, Default: "", Enum: []any{"fast", "slow"}}, wantErr: true},
+		{name: "zero default below minimum", option: &paramSpec{Name: "count", Type: jsonschema.Integer, Default: 0, paramConstraints: paramConstraints{Minimum: &minimum}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := (&scaffoldAction{runtime: runtimePlugin, id: "test", title: "Test"}).getDefaultValues()
+			err := (&actionSpec{Options: []*paramSpec{tt.option}}).apply(values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			f, err := emitDefinition(values)
+			if err != nil {
+				t.Fatalf("emitDefinition() error = %v", err)
+			}
+			var doc any
+			err = yaml.Unmarshal(f.content, &doc)
+			if err != nil {
+				t.Fatalf("invalid yaml:\n%s\nerror: %v", f.content, err)
+			}
+
+			got, ok := yamlPath(doc, "action.options.0.default")
+			switch {
+			case tt.absent && ok:
+				t.Errorf("default %v is emitted in:\n%s", got, f.content)
+			case !tt.absent && !reflect.DeepEqual(got, tt.want):
+				t.Errorf("default = %#v, want %#v", got, tt.want)
+			}
+
+			err = verifyDefinition(values.ID, f.content)
+			if err != nil {
+				t.Errorf("emitted definition is invalid: %v\n%s", err, f.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package scaffold
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/launchrctl/launchr"
+	"github.com/launchrctl/launchr/pkg/action"
+	"gopkg.in/yaml.v3"
+)
+
+// errSummaryCancelled is returned when the user cancels generation on the summary screen
+var errSummaryCancelled = errors.New("generation cancelled")
+
+// summaryChoice is a choice of the summary screen
+type summaryChoice int
+
+const (
+	summaryGenerate   summaryChoice = iota // Write the files
+	summaryEdit                            // Edit action.yaml in $EDITOR
+	summaryGeneral                         // Go back to the title, aliases, runtime and ID
+	summaryParameters                      // Go back to the parameters review
+	summaryRuntime                         // Go back to the runtime configuration
+	summaryCancel                          // Exit without writing anything
+)
+
+// confirmSummary shows action.yaml and the file tree to be generated and lets the user
+// generate the action, edit the definition, go back to a group of questions or cancel
+func (m *metadataCollector) confirmSummary(values *templateValues, newGen func(*templateValues) *generator) error {
+	// draft keeps an invalid edited definition, so the next edit doesn't start over.
+	var draft []byte
+	for {
+		gen := newGen(values)
+		files, err := gen.render(values)
+		if err != nil {
+			return err
+		}
+
+		actionDir := gen.dirManager.getActionDir(values.ID)
+		nonEmpty, err := gen.dirManager.isNonEmpty(actionDir)
+		if err != nil {
+			return err
+		}
+		definition := printSummary(actionDir, files, nonEmpty && gen.opts.conflict == conflictFail)
+
+		options := []huh.Option[summaryChoice]{huh.NewOption("Generate", summaryGenerate)}
+		// An editor needs a terminal.
+		if m.prompt.terminal {
+			options = append(options, huh.NewOption("Edit action.yaml in $EDITOR", summaryEdit))
+		}
+		options = append(options,
+			huh.NewOption("Back to title, aliases, runtime and ID", summaryGeneral),
+			huh.NewOption("Back to arguments and options", summaryParameters),
+			huh.NewOption("Back to runtime configuration", summaryRuntime),
+			huh.NewOption("Cancel", summaryCancel),
+		)
+
+		choice := summaryGenerate
+		err = m.runForm(
+			huh.NewGroup(
+				huh.NewSelect[summaryChoice]().
+					Title("Generate the action?").
+					Options(options...).
+					Value(&choice),
+			),
+		)
+		if err != nil {
+			return fmt.Errorf("form error: %w", err)
+		}
+
+		switch choice {
+		case summaryGenerate:
+			return nil
+		case summaryCancel:
+			return errSummaryCancelled
+		case summaryEdit:
+			if draft == nil {
+				draft = definition
+			}
+			edited, err := editInEditor(draft)
+			if err != nil {
+				return err
+			}
+
+			err = m.applyEditedDefinition(values, edited)
+			if err != nil {
+				draft = edited
+				launchr.Term().Error().Printfln("Edited action.yaml is invalid, it's not used: %s", err)
+				continue
+			}
+			draft = nil
+			launchr.Term().Success().Printfln("Edited action.yaml will be used")
+		default:
+			if values.editedDefinition != nil {
+				launchr.Term().Warning().Printfln("Changes of action.yaml made in the editor are discarded")
+				values.editedDefinition = nil
+			}
+
+			err = m.revisit(choice, values)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// revisit shows a group of questions again and completes the changed values
+func (m *metadataCollector) revisit(choice summaryChoice, values *templateValues) error {
+	var err error
+	switch choice {
+	case summaryGeneral:
+		err = m.collectGeneralInfo(values)
+	case summaryParameters:
+		err = m.reviewParameters(values)
+	case summaryRuntime:
+		err = m.collectRuntimeData(values)
+	}
+	if err != nil {
+		return err
+	}
+
+	return m.complete(values)
+}
+
+// printSummary prints the file tree and action.yaml to be generated, it returns the printed definition
+func printSummary(actionDir string, files []*renderedFile, conflict bool) []byte {
+	launchr.Term().Info().Printfln("Action will be generated in %s", actionDir)
+	if conflict {
+		launchr.Term().Warning().Printfln("Directory %s is not empty, generation will fail without --force or --merge", actionDir)
+	}
+	launchr.Term().Print(formatFileTree(actionDir, renderedPaths(files)))
+
+	for _, f := range files {
+		if f.path == "action.yaml" {
+			launchr.Term().Info().Printfln("%s", filepath.Join(actionDir, f.path))
+			launchr.Term().Println(string(f.content))
+			return f.content
+		}
+	}
+
+	return nil
+}
+
+// editInEditor opens content in $VISUAL or $EDITOR and returns the edited content
+func editInEditor(content []byte) ([]byte, error) {
+	editor := strings.Fields(cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi"))
+
+	f, err := os.CreateTemp("", "action-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a file to edit: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	_, err = f.Write(content)
+	_ = f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write a file to edit: %w", err)
+	}
+
+	// #nosec G204 -- The editor is chosen by the user running the command.
+	cmd := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	return os.ReadFile(filepath.Clean(f.Name()))
+}
+
+// applyEditedDefinition validates an edited action.yaml and makes it the source of truth,
+// collected values are updated from it for other templates and the answers file
+func (m *metadataCollector) applyEditedDefinition(values *templateValues, data []byte) error {
+	err := verifyDefinition(values.ID, data)
+	if err != nil {
+		return err
+	}
+
+	def, err := action.NewDefFromYaml(data)
+	if err != nil {
+		return err
+	}
+	if def == nil || def.Action == nil || def.Runtime == nil {
+		return fmt.Errorf("action and runtime sections are required")
+	}
+	// Templates refer to both runtime sections whatever the runtime type is.
+	if def.Runtime.Container == nil {
+		def.Runtime.Container = &action.DefRuntimeContainer{}
+	}
+	if def.Runtime.Shell == nil {
+		def.Runtime.Shell = &action.DefRuntimeShell{}
+	}
+
+	constraints, err := definitionConstraints(data)
+	if err != nil {
+		return err
+	}
+
+	// Values are changed only if the edited definition is valid as a whole.
+	edited := *values
+	edited.Definition = def
+	edited.Constraints = constraints
+	// Everything in the edited definition is given by the user, nothing is derived anymore.
+	edited.derived = derivedValues{}
+	err = m.validate(&edited)
+	if err != nil {
+		return err
+	}
+
+	edited.editedDefinition = data
+	*values = edited
+	return nil
+}
+
+// definitionConstraints reads constraints of parameters declared in action.yaml
+func definitionConstraints(data []byte) (map[string]*paramConstraints, error) {
+	var def struct {
+		Action struct {
+			Arguments []*paramSpec `yaml:"arguments"`
+			Options   []*paramSpec `yaml:"options"`
+		} `yaml:"action"`
+	}
+	err := yaml.Unmarshal(data, &def)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := make(map[string]*paramConstraints)
+	for _, ps := range append(def.Action.Arguments, def.Action.Options...) {
+		if !ps.paramConstraints.isEmpty() {
+			c := ps.paramConstraints
+			constraints[ps.Name] = &c
+		}
+	}
+
+	return constraints, nil
+}
@@ -0,0 +1,197 @@
+package scaffold
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/launchrctl/launchr/pkg/action"
+)
+
+// testManager is an action manager holding only the given actions
+type testManager struct {
+	action.Manager
+	actions map[string]*action.Action
+}
+
+func (m *testManager) All() map[string]*action.Action {
+	return m.actions
+}
+
+func (m *testManager) Get(id string) (*action.Action, bool) {
+	a, ok := m.actions[id]
+	return a, ok
+}
+
+// newTestCollector creates a non-interactive collector with embedded templates and the discovered actions
+func newTestCollector(t *testing.T, actions map[string]*action.Action) *metadataCollector {
+	t.Helper()
+	if actions == nil {
+		actions = make(map[string]*action.Action)
+	}
+	manager := &testManager{actions: actions}
+	templates := newTemplateManager(embeddedTemplates(), newPresetRegistry())
+	return newMetadataCollector(manager, nil, &scaffoldConfig{}, templates, promptOptions{}, t.TempDir(), false)
+}
+
+func TestApplyEditedDefinition(t *testing.T) {
+	other := action.NewFromYAML("other", []byte("action:\n  title: Other\n  alias:\n    - taken\n\nruntime: plugin\n"))
+
+	tests := []struct {
+		name       string
+		definition string
+		check      func(t *testing.T, values *templateValues)
+		wantErr    bool
+	}{
+		{
+			name: "container runtime",
+			definition: `action:
+  title: Edited
+  alias:
+    - ed
+  options:
+    - name: count
+      type: integer
+      minimum: 1
+      default: 2
+
+runtime:
+  type: container
+  image: registry.local/edited:1.0
+  env:
+    - FOO=bar
+  command:
+    - sh
+    - /action/edited.sh
+`,
+			check: func(t *testing.T, values *templateValues) {
+				if values.Action.Title != "Edited" || !reflect.DeepEqual(values.Action.Aliases, []string{"ed"}) {
+					t.Errorf("action = %+v, want the edited one", values.Action)
+				}
+				c := values.Runtime.Container
+				if c.Image != "registry.local/edited:1.0" {
+					t.Errorf("image = %q, want the edited one", c.Image)
+				}
+				if got := []string(c.Env); !reflect.DeepEqual(got, []string{"FOO=bar"}) {
+					t.Errorf("env = %v, want the edited one", got)
+				}
+				if got := []string(c.Command); !reflect.DeepEqual(got, []string{"sh", "/action/edited.sh"}) {
+					t.Errorf("command = %v, want the edited one", got)
+				}
+				if values.Runtime.Shell == nil {
+					t.Errorf("shell section is nil")
+				}
+				if values.Constraints["count"] == nil {
+					t.Errorf("constraints of count are dropped")
+				}
+				if values.derived != (derivedValues{}) {
+					t.Errorf("edited values are marked as derived: %+v", values.derived)
+				}
+			},
+		},
+		{
+			name:       "shell runtime",
+			definition: "action:\n  title: Edited\n\nruntime:\n  type: shell\n  script: echo edited\n",
+			check: func(t *testing.T, values *templateValues) {
+				if values.Runtime.Type != runtimeShell || values.Runtime.Shell.Script != "echo edited" {
+					t.Errorf("runtime = %+v, want the edited shell runtime", values.Runtime)
+				}
+				if values.Runtime.Container == nil {
+					t.Errorf("container section is nil")
+				}
+			},
+		},
+		{
+			name:       "invalid image",
+			definition: "action:\n  title: Edited\n\nruntime:\n  type: container\n  image: Bad Image\n",
+			wantErr:    true,
+		},
+		{
+			name:       "alias of another action",
+			definition: "action:\n  title: Edited\n  alias:\n    - taken\n\nruntime:\n  type: container\n  image: edited:1.0\n",
+			wantErr:    true,
+		},
+		{
+			name:       "invalid default",
+			definition: "action:\n  title: Edited\n  options:\n    - name: count\n      type: integer\n      default: many\n\nruntime: plugin\n",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestCollector(t, map[string]*action.Action{"other": other})
+			values := (&scaffoldAction{runtime: runtimeContainer, id: "test", title: "Test", containerPreset: "sh"}).getDefaultValues()
+			if err := m.complete(values); err != nil {
+				t.Fatal(err)
+			}
+			before := *values.Definition
+			beforeRuntime := *values.Runtime
+
+			err := m.applyEditedDefinition(values, []byte(tt.definition))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyEditedDefinition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				// An invalid edit doesn't change collected values.
+				if values.editedDefinition != nil || values.Action != before.Action || *values.Runtime != beforeRuntime {
+					t.Errorf("values are changed by an invalid definition")
+				}
+				return
+			}
+
+			if string(values.editedDefinition) != tt.definition {
+				t.Errorf("edited definition is not stored")
+			}
+			tt.check(t, values)
+		})
+	}
+}
+
+func TestDeriveValuesFollowChanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     []string // Command given by the user
+		image       string   // Image given by the user
+		wantCommand []string // Command after the preset change
+		wantImage   string   // Image after the ID change
+	}{
+		{
+			name:        "derived",
+			wantCommand: []string{"python3", "-B", "/action/main.py"},
+			wantImage:   "renamed:latest",
+		},
+		{
+			name:        "explicit",
+			command:     []string{"sh", "/action/custom.sh"},
+			image:       "registry.local/custom:1.0",
+			wantCommand: []string{"sh", "/action/custom.sh"},
+			wantImage:   "registry.local/custom:1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestCollector(t, nil)
+			values := (&scaffoldAction{runtime: runtimeContainer, id: "test", title: "Test", containerPreset: "sh"}).getDefaultValues()
+			values.Runtime.Container.Command = tt.command
+			values.Runtime.Container.Image = tt.image
+			if err := m.complete(values); err != nil {
+				t.Fatal(err)
+			}
+
+			// The ID and preset are changed from the summary screen.
+			values.ID = "renamed"
+			values.ContainerPreset = "py"
+			if err := m.complete(values); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := []string(values.Runtime.Container.Command); !reflect.DeepEqual(got, tt.wantCommand) {
+				t.Errorf("command = %v, want %v", got, tt.wantCommand)
+			}
+			if got := values.Runtime.Container.Image; got != tt.wantImage {
+				t.Errorf("image = %q, want %q", got, tt.wantImage)
+			}
+		})
+	}
+}
@@ -1,10 +1,11 @@
 package scaffold
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
+	"go/format"
 	"io/fs"
-	"os"
 	"path"
 	"path/filepath"
 	"slices"
@@ -14,28 +15,94 @@ import (
 	"github.com/launchrctl/launchr/pkg/action"
 )
 
-//go:embed templates/*
+//go:embed all:templates
 var templateFS embed.FS
 
-const templatesFilesDir = "templates/files"
-const templatesDefinitionDir = "templates/definition"
+// templatesVersion is recorded in answers files, bump it when templates output changes
+const templatesVersion = "3"
+
+// templatesFilesDir holds action files of each runtime, e.g. "files/shell/main.sh.tmpl"
+const templatesFilesDir = "files"
+
+// templatesDefinitionDir holds the action.yaml templates, the common part and one per runtime
+const templatesDefinitionDir = "definition"
+
+// embeddedTemplates returns the default templates shipped with the plugin.
+// Paths of template directories are relative to its root.
+func embeddedTemplates() fs.FS {
+	sub, err := fs.Sub(templateFS, "templates")
+	if err != nil {
+		// Unreachable, the path is a valid constant.
+		panic(err)
+	}
+
+	return sub
+}
 
 // templateManager orchestrates a template collection, preparation and delivery
-type templateManager struct{}
+type templateManager struct {
+	fs      fs.FS // Templates root
+	presets *presetRegistry
+}
+
+// newTemplateManager creates a template manager reading templates from the given filesystem
+func newTemplateManager(fsys fs.FS, presets *presetRegistry) *templateManager {
+	return &templateManager{
+		fs:      fsys,
+		presets: presets,
+	}
+}
+
+// getFilesDir returns the files templates directory and the preset for the chosen runtime and preset
+func (t *templateManager) getFilesDir(values *templateValues) (string, *Preset, error) {
+	p, err := t.presets.resolve(values)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir := string(values.Runtime.Type)
+	if p != nil {
+		dir = p.filesDir
+	}
+
+	return path.Join(templatesFilesDir, dir), p, nil
+}
+
+// getFilesFS returns templates of action files for the chosen runtime and preset
+func (t *templateManager) getFilesFS(values *templateValues) (fs.FS, error) {
+	dir, p, err := t.getFilesDir(values)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := fs.Sub(t.fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if p == nil || p.Templates == nil {
+		return sub, nil
+	}
+
+	// Files in templates directories override files of a contributed preset.
+	return newLayeredFS([]templateSource{
+		{origin: "templates directories", fs: sub},
+		{origin: p.origin, fs: p.Templates},
+	}), nil
+}
 
-// getTemplateSubdirectories returns all subdirectories within a given path in the embedded filesystem
-func (t *templateManager) getTemplateSubdirectories(dirPath string) ([]string, error) {
-	entries, err := fs.ReadDir(templateFS, filepath.Join(templatesFilesDir, dirPath))
+// getTemplateSubdirectories returns all subdirectories within a given path of the files templates
+func (t *templateManager) getTemplateSubdirectories(fsys fs.FS, dirPath string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dirPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read embedded directory %s: %w", dirPath, err)
+		return nil, fmt.Errorf("failed to read templates directory %s: %w", dirPath, err)
 	}
 
 	var directories []string
 	for _, entry := range entries {
 		if entry.IsDir() {
 			subDirPath := path.Join(dirPath, entry.Name())
-			directories = append(directories, subDirPath)
-			subDirs, err := t.getTemplateSubdirectories(subDirPath)
+			subDirs, err := t.getTemplateSubdirectories(fsys, subDirPath)
 			if err != nil {
 				return nil, err
 			}
@@ -48,30 +115,66 @@ func (t *templateManager) getTemplateSubdirectories(dirPath string) ([]string, e
 	return directories, nil
 }
 
-func (t *templateManager) renderTemplates(output string, values *templateValues, templates []*template.Template) error {
+// renderedFile is a template output kept in memory until it is written or previewed
+type renderedFile struct {
+	path    string // Path relative to the action directory
+	content []byte
+}
+
+// outputName returns the name of a file rendered from the template
+func outputName(tmplName string) string {
+	return strings.Replace(tmplName, ".tmpl", "", 1)
+}
+
+// origin returns where a file of the templates root comes from, empty if the file doesn't exist
+func (t *templateManager) origin(name string) string {
+	if l, ok := t.fs.(*layeredFS); ok {
+		return l.origin(name)
+	}
+
+	if _, err := fs.Stat(t.fs, name); err != nil {
+		return ""
+	}
+
+	return "embedded"
+}
+
+// renderTemplates executes templates in memory, dir is relative to the output directory
+func (t *templateManager) renderTemplates(dir string, data any, templates []*template.Template) ([]*renderedFile, error) {
+	files := make([]*renderedFile, 0, len(templates))
 	for _, t := range templates {
-		outputPath := filepath.Clean(filepath.Join(output, strings.Replace(t.Name(), ".tmpl", "", 1)))
-		outFile, err := os.Create(outputPath)
+		var buf bytes.Buffer
+		err := t.Execute(&buf, data)
 		if err != nil {
-			return fmt.Errorf("failed to create output file %s: %w", outputPath, err)
+			return nil, err
 		}
 
-		err = t.Execute(outFile, values)
-		if err != nil {
-			return err
+		name := outputName(t.Name())
+		content := buf.Bytes()
+		// Templates don't have to align Go code, invalid code is kept as is for verification to report it.
+		if filepath.Ext(name) == ".go" {
+			formatted, err := format.Source(content)
+			if err == nil {
+				content = formatted
+			}
 		}
-		_ = outFile.Close()
+
+		files = append(files, &renderedFile{
+			path:    filepath.Join(dir, name),
+			content: content,
+		})
 	}
 
-	return nil
+	return files, nil
 }
 
 // getDefinitionTemplate creates the action.yaml file from templates
 func (t *templateManager) getDefinitionTemplate(runtimeType action.DefRuntimeType) (*template.Template, error) {
 	tmpl, err := template.New("action.yaml").
-		ParseFS(templateFS,
-			filepath.Join(templatesDefinitionDir, "action.yaml.tmpl"),
-			filepath.Join(templatesDefinitionDir, fmt.Sprintf("%s.yaml.tmpl", runtimeType)),
+		Funcs(templateFuncs).
+		ParseFS(t.fs,
+			path.Join(templatesDefinitionDir, "action.yaml.tmpl"),
+			path.Join(templatesDefinitionDir, fmt.Sprintf("%s.yaml.tmpl", runtimeType)),
 		)
 	if err != nil {
 		return nil, err
@@ -94,13 +197,41 @@ func (t *templateManager) getDefinitionTemplate(runtimeType action.DefRuntimeTyp
 	return combinedTmpl, err
 }
 
-func (t *templateManager) getRuntimeTemplates(dir string) ([]*template.Template, error) {
-	tmpl := template.New("")
+// renderTree executes templates of every directory of the filesystem keeping the directory structure
+func (t *templateManager) renderTree(fsys fs.FS, data any) ([]*renderedFile, error) {
+	dirs, err := t.getTemplateSubdirectories(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*renderedFile
+	for _, d := range dirs {
+		templates, err := t.getRuntimeTemplates(fsys, d)
+		if err != nil {
+			if strings.Contains(err.Error(), "template: pattern matches no files") {
+				continue
+			}
+
+			return nil, err
+		}
+
+		rendered, err := t.renderTemplates(d, data, templates)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rendered...)
+	}
+
+	return files, nil
+}
+
+func (t *templateManager) getRuntimeTemplates(fsys fs.FS, dir string) ([]*template.Template, error) {
+	tmpl := template.New("").Funcs(templateFuncs)
 	var err error
 
-	patterns := []string{filepath.Join(templatesFilesDir, dir, "*.tmpl")}
+	patterns := []string{path.Join(dir, "*.tmpl")}
 
-	tmpl, err = tmpl.ParseFS(templateFS, patterns...)
+	tmpl, err = tmpl.ParseFS(fsys, patterns...)
 	if err != nil {
 		return nil, err
 	}
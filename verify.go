@@ -0,0 +1,236 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// definitionError is a validation error of a generated action.yaml pointing at the offending line
+type definitionError struct {
+	line int // 0 if the line is unknown
+	err  error
+}
+
+func (e *definitionError) Error() string {
+	// Parser errors already mention the line.
+	if e.line == 0 || yamlErrorLineRegex.MatchString(e.err.Error()) {
+		return e.err.Error()
+	}
+
+	return fmt.Sprintf("line %d: %s", e.line, e.err)
+}
+
+func (e *definitionError) Unwrap() error {
+	return e.err
+}
+
+var yamlErrorLineRegex = regexp.MustCompile(`line (\d+)`)
+
+// newDefinitionError wraps an error of the YAML parser or launchr loader, the line is taken from the message
+func newDefinitionError(err error) error {
+	var defErr *definitionError
+	if errors.As(err, &defErr) {
+		return err
+	}
+
+	line := 0
+	if m := yamlErrorLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		line, _ = strconv.Atoi(m[1])
+	}
+
+	return &definitionError{line: line, err: err}
+}
+
+// verifyDefinition loads the rendered action.yaml the same way launchr loads discovered actions
+// and checks declared parameters and their default values
+func verifyDefinition(id string, data []byte) error {
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return newDefinitionError(err)
+	}
+
+	_, err = action.NewFromYAML(id, data).Raw()
+	if err != nil {
+		return newDefinitionError(err)
+	}
+
+	if len(doc.Content) == 0 {
+		return &definitionError{err: fmt.Errorf("definition is empty")}
+	}
+
+	act := yamlMapValue(doc.Content[0], "action")
+	if act == nil {
+		return &definitionError{line: 1, err: fmt.Errorf("missing action section")}
+	}
+
+	for _, kind := range []string{"arguments", "options"} {
+		err = verifyParameters(yamlMapValue(act, kind))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyDefinitionFile verifies action.yaml written to the action directory
+func verifyDefinitionFile(id, actionDir string) error {
+	path := filepath.Join(actionDir, "action.yaml")
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	err = verifyDefinition(id, data)
+	if err != nil {
+		return fmt.Errorf("%s is invalid: %w", path, err)
+	}
+
+	return nil
+}
+
+// verifyRenderedDefinition verifies action.yaml among rendered files the same way as the written one
+func verifyRenderedDefinition(id string, files []*renderedFile) error {
+	for _, f := range files {
+		if f.path != "action.yaml" {
+			continue
+		}
+
+		err := verifyDefinition(id, f.content)
+		if err != nil {
+			return fmt.Errorf("%s is invalid: %w", f.path, err)
+		}
+	}
+
+	return nil
+}
+
+// verifyParameters checks types and default values of a parameters list node
+func verifyParameters(params *yaml.Node) error {
+	if params == nil {
+		return nil
+	}
+
+	for _, p := range params.Content {
+		name := yamlMapValue(p, "name")
+		if name == nil || name.Value == "" {
+			return &definitionError{line: p.Line, err: fmt.Errorf("parameter name is missing")}
+		}
+
+		typeNode := yamlMapValue(p, "type")
+		paramType := jsonschema.String
+		if typeNode != nil {
+			paramType = jsonschema.Type(typeNode.Value)
+			if !slices.Contains(specTypes, paramType) {
+				return &definitionError{line: typeNode.Line, err: fmt.Errorf("parameter '%s' has unsupported type '%s'", name.Value, paramType)}
+			}
+		}
+
+		itemsType := jsonschema.String
+		if items := yamlMapValue(p, "items"); items != nil {
+			if t := yamlMapValue(items, "type"); t != nil {
+				itemsType = jsonschema.Type(t.Value)
+			}
+		}
+
+		def := yamlMapValue(p, "default")
+		if def == nil {
+			continue
+		}
+
+		var v any
+		err := def.Decode(&v)
+		if err != nil {
+			return &definitionError{line: def.Line, err: err}
+		}
+
+		err = checkDefaultType(paramType, itemsType, v)
+		if err != nil {
+			return &definitionError{line: def.Line, err: fmt.Errorf("default value of parameter '%s': %w", name.Value, err)}
+		}
+
+		err = verifyConstraints(p, name.Value, paramType, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyConstraints checks constraints of a parameter node are consistent and satisfied by the default value
+func verifyConstraints(p *yaml.Node, name string, paramType jsonschema.Type, def any) error {
+	var ps paramSpec
+	err := p.Decode(&ps)
+	if err != nil {
+		return &definitionError{line: p.Line, err: err}
+	}
+
+	param := &action.DefParameter{Name: name, Type: paramType, Default: def, Enum: ps.Enum}
+	err = checkConstraints(param, &ps.paramConstraints)
+	if err != nil {
+		return &definitionError{line: p.Line, err: fmt.Errorf("invalid constraints of parameter '%s': %w", name, err)}
+	}
+
+	if def == nil {
+		return nil
+	}
+
+	err = checkConstrainedValue(param, &ps.paramConstraints, def)
+	if err != nil {
+		return &definitionError{line: yamlMapValue(p, "default").Line, err: fmt.Errorf("default value of parameter '%s' %w", name, err)}
+	}
+
+	return nil
+}
+
+// checkDefaultType checks a decoded default value matches the declared type
+func checkDefaultType(t, itemsType jsonschema.Type, v any) error {
+	if v == nil {
+		return nil
+	}
+
+	ok := false
+	switch t {
+	case jsonschema.String:
+		_, ok = v.(string)
+	case jsonschema.Integer:
+		_, ok = v.(int)
+	case jsonschema.Number:
+		switch v.(type) {
+		case int, float64:
+			ok = true
+		}
+	case jsonschema.Boolean:
+		_, ok = v.(bool)
+	case jsonschema.Array:
+		items, isList := v.([]any)
+		if !isList {
+			break
+		}
+		for _, item := range items {
+			if err := checkDefaultType(itemsType, "", item); err != nil {
+				return fmt.Errorf("array item: %w", err)
+			}
+		}
+		ok = true
+	default:
+		ok = true
+	}
+
+	if !ok {
+		return fmt.Errorf("%v (%T) is not of type %s", v, v, t)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package scaffold
+
+import "testing"
+
+func TestVerifyDefinitionDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  string // Option of the definition
+		wantErr bool
+	}{
+		{name: "enum without default", option: "name: mode\n      enum: [fast, slow]"},
+		{name: "minimum without default", option: "name: count\n      type: integer\n      minimum: 1"},
+		{name: "null default", option: "name: count\n      type: integer\n      minimum: 1\n      default: ~"},
+		{name: "empty default out of enum", option: "name: mode\n      enum: [fast, slow]\n      default: \"\"", wantErr: true},
+		{name: "zero default below minimum", option: "name: count\n      type: integer\n      minimum: 1\n      default: 0", wantErr: true},
+		{name: "false default out of enum", option: "name: debug\n      type: boolean\n      enum: [true]\n      default: false", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyDefinition("test", []byte("action:\n  title: Test\n  options:\n    - "+tt.option+"\n\nruntime: plugin\n"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyDefinition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/launchrctl/launchr"
+)
+
+// fileWriter writes rendered files through a staging directory and remembers
+// everything it changed in the destination, so a failed run can be rolled back
+// without touching pre-existing content.
+type fileWriter struct {
+	createdDirs  []string
+	createdFiles []string
+	overwritten  map[string]*overwrittenFile
+	movedDir     string // Action directory moved into place as a whole, it contains only our files
+}
+
+// overwrittenFile is the previous state of a file replaced by a generated one
+type overwrittenFile struct {
+	content []byte
+	mode    fs.FileMode
+}
+
+func newFileWriter() *fileWriter {
+	return &fileWriter{
+		overwritten: make(map[string]*overwrittenFile),
+	}
+}
+
+// writeFiles renders files into a staging directory next to the action directory
+// and moves them into place only when every file was written successfully.
+// The context is checked between files, the caller rolls back changes made before the cancellation.
+func (w *fileWriter) writeFiles(ctx context.Context, actionDir string, files []*renderedFile) error {
+	parentDir := filepath.Dir(actionDir)
+	err := w.mkdirAll(parentDir)
+	if err != nil {
+		return err
+	}
+
+	// Staging directory lives next to the destination to keep renames on the same filesystem.
+	stagingDir, err := os.MkdirTemp(parentDir, fmt.Sprintf(".%s-scaffold-*", filepath.Base(actionDir)))
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() {
+		// The staging directory is always ours, it's gone already if it was moved into place.
+		if err := os.RemoveAll(stagingDir); err != nil {
+			launchr.Term().Warning().Printfln("Failed to clean up staging directory %s: %v", stagingDir, err)
+		}
+	}()
+
+	err = stageFiles(ctx, stagingDir, files)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(actionDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The whole action directory appears at once.
+		if err = checkInterrupted(ctx); err != nil {
+			return err
+		}
+		if err = os.Chmod(stagingDir, 0750); err != nil {
+			return fmt.Errorf("failed to prepare staging directory: %w", err)
+		}
+		if err = os.Rename(stagingDir, actionDir); err != nil {
+			return fmt.Errorf("failed to move action directory into place: %w", err)
+		}
+		w.movedDir = actionDir
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read action directory %s: %w", actionDir, err)
+	}
+
+	// The action directory already exists, move files one by one.
+	return w.moveFiles(ctx, stagingDir, actionDir, files)
+}
+
+// checkInterrupted returns an error if writing is cancelled
+func checkInterrupted(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("writing files was interrupted: %w", err)
+	}
+
+	return nil
+}
+
+// stageFiles writes rendered files into the staging directory
+func stageFiles(ctx context.Context, stagingDir string, files []*renderedFile) error {
+	for _, f := range files {
+		if err := checkInterrupted(ctx); err != nil {
+			return err
+		}
+
+		outputPath := filepath.Clean(filepath.Join(stagingDir, f.path))
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0750); err != nil {
+			return fmt.Errorf("failed to create staging directory: %w", err)
+		}
+
+		outFile, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file %s: %w", f.path, err)
+		}
+
+		_, err = outFile.Write(f.content)
+		_ = outFile.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write output file %s: %w", f.path, err)
+		}
+	}
+
+	return nil
+}
+
+// moveFiles moves staged files into an existing action directory, keeping the previous content of overwritten files
+func (w *fileWriter) moveFiles(ctx context.Context, stagingDir, actionDir string, files []*renderedFile) error {
+	for _, f := range files {
+		// Files moved so far are recorded, so they're rolled back along with the rest.
+		if err := checkInterrupted(ctx); err != nil {
+			return err
+		}
+
+		outputPath := filepath.Clean(filepath.Join(actionDir, f.path))
+		err := w.mkdirAll(filepath.Dir(outputPath))
+		if err != nil {
+			return err
+		}
+
+		prev, err := readExisting(outputPath)
+		if err != nil {
+			return err
+		}
+
+		err = os.Rename(filepath.Join(stagingDir, f.path), outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to move file %s into place: %w", outputPath, err)
+		}
+
+		if prev != nil {
+			w.overwritten[outputPath] = prev
+		} else {
+			w.createdFiles = append(w.createdFiles, outputPath)
+		}
+	}
+
+	return nil
+}
+
+// readExisting reads a file about to be overwritten, nil is returned if there is no such file
+func readExisting(path string) (*overwrittenFile, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing file %s: %w", path, err)
+	}
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing file %s: %w", path, err)
+	}
+
+	return &overwrittenFile{content: content, mode: info.Mode().Perm()}, nil
+}
+
+// mkdirAll creates a directory along with missing parents and records every directory it created
+func (w *fileWriter) mkdirAll(dir string) error {
+	var missing []string
+	for d := filepath.Clean(dir); ; {
+		_, err := os.Stat(d)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to create action directory: %w", err)
+		}
+
+		missing = append(missing, d)
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+
+	// Create from the top-most missing directory down.
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0750); err != nil {
+			return fmt.Errorf("failed to create action directory: %w", err)
+		}
+		w.createdDirs = append(w.createdDirs, missing[i])
+	}
+
+	return nil
+}
+
+// rollback restores overwritten files and removes files and directories created by this writer
+func (w *fileWriter) rollback() {
+	if w.movedDir != "" {
+		if err := os.RemoveAll(w.movedDir); err != nil {
+			launchr.Term().Warning().Printfln("Failed to clean up directory %s: %v", w.movedDir, err)
+		}
+	}
+
+	for path, prev := range w.overwritten {
+		// The file in place is the moved staged one, its mode is restored along with the content.
+		err := os.WriteFile(path, prev.content, prev.mode)
+		if err == nil {
+			err = os.Chmod(path, prev.mode)
+		}
+		if err != nil {
+			launchr.Term().Warning().Printfln("Failed to restore file %s: %v", path, err)
+		}
+	}
+
+	for i := len(w.createdFiles) - 1; i >= 0; i-- {
+		if err := os.Remove(w.createdFiles[i]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			launchr.Term().Warning().Printfln("Failed to clean up file %s: %v", w.createdFiles[i], err)
+		}
+	}
+
+	// Directories are removed deepest first, os.Remove never deletes a non-empty directory.
+	for i := len(w.createdDirs) - 1; i >= 0; i-- {
+		if err := os.Remove(w.createdDirs[i]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			launchr.Term().Warning().Printfln("Failed to clean up directory %s: %v", w.createdDirs[i], err)
+		}
+	}
+}
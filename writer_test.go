@@ -0,0 +1,273 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type testFile struct {
+	content string
+	mode    fs.FileMode
+}
+
+// writeTestFiles creates files relative to the directory
+func writeTestFiles(t *testing.T, dir string, files map[string]testFile) {
+	t.Helper()
+	for name, f := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f.content), f.mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, f.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTestFiles reads every file under the directory, nil is returned if it doesn't exist
+func readTestFiles(t *testing.T, dir string) map[string]testFile {
+	t.Helper()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	files := make(map[string]testFile)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = testFile{content: string(content), mode: info.Mode().Perm()}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return files
+}
+
+func TestFileWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing map[string]testFile // Files in the action directory before the run
+		files    []*renderedFile
+		want     map[string]string // Content of the action directory after the run
+	}{
+		{
+			name: "new directory",
+			files: []*renderedFile{
+				{path: "action.yaml", content: []byte("action: {}")},
+				{path: "src/main.sh", content: []byte("echo")},
+			},
+			want: map[string]string{"action.yaml": "action: {}", "src/main.sh": "echo"},
+		},
+		{
+			name: "existing directory",
+			existing: map[string]testFile{
+				"action.yaml": {content: "old", mode: 0600},
+				"main.sh":     {content: "#!/bin/sh\necho old", mode: 0755},
+				"notes.txt":   {content: "hand-written", mode: 0644},
+			},
+			files: []*renderedFile{
+				{path: "action.yaml", content: []byte("new")},
+				{path: "main.sh", content: []byte("echo new")},
+				{path: "lib/a/b.sh", content: []byte("b")},
+			},
+			want: map[string]string{
+				"action.yaml": "new",
+				"main.sh":     "echo new",
+				"notes.txt":   "hand-written",
+				"lib/a/b.sh":  "b",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := filepath.Join(t.TempDir(), "actions")
+			actionDir := filepath.Join(parent, "myaction")
+			if tt.existing != nil {
+				writeTestFiles(t, actionDir, tt.existing)
+			}
+
+			w := newFileWriter()
+			err := w.writeFiles(context.Background(), actionDir, tt.files)
+			if err != nil {
+				t.Fatalf("writeFiles() error = %v", err)
+			}
+
+			got := readTestFiles(t, actionDir)
+			if len(got) != len(tt.want) {
+				t.Errorf("files after write = %v, want %v", got, tt.want)
+			}
+			for name, content := range tt.want {
+				if got[name].content != content {
+					t.Errorf("file %s = %q, want %q", name, got[name].content, content)
+				}
+			}
+
+			entries, err := os.ReadDir(parent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				if strings.Contains(e.Name(), "-scaffold-") {
+					t.Errorf("staging directory %s is left behind", e.Name())
+				}
+			}
+
+			w.rollback()
+
+			got = readTestFiles(t, actionDir)
+			if len(got) != len(tt.existing) {
+				t.Errorf("files after rollback = %v, want %v", got, tt.existing)
+			}
+			for name, f := range tt.existing {
+				if got[name].content != f.content {
+					t.Errorf("restored file %s = %q, want %q", name, got[name].content, f.content)
+				}
+				// Windows doesn't keep unix permissions.
+				if runtime.GOOS != "windows" && got[name].mode != f.mode {
+					t.Errorf("restored file %s mode = %v, want %v", name, got[name].mode, f.mode)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(actionDir, "lib")); !os.IsNotExist(err) {
+				t.Errorf("created directory %s is not removed", filepath.Join(actionDir, "lib"))
+			}
+			if _, err := os.Stat(parent); tt.existing == nil && !os.IsNotExist(err) {
+				t.Errorf("created directory %s is not removed", parent)
+			}
+		})
+	}
+}
+
+func TestFileWriterFailure(t *testing.T) {
+	actionDir := filepath.Join(t.TempDir(), "myaction")
+	writeTestFiles(t, actionDir, map[string]testFile{"main.sh": {content: "old", mode: 0600}})
+
+	// A file can't be moved over a directory, the writer fails in the middle of the run.
+	if err := os.Mkdir(filepath.Join(actionDir, "taken"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(actionDir, "taken", "file"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w := newFileWriter()
+	err := w.writeFiles(context.Background(), actionDir, []*renderedFile{
+		{path: "main.sh", content: []byte("new")},
+		{path: "taken", content: []byte("file")},
+	})
+	if err == nil {
+		t.Fatal("writeFiles() error = nil, want an error")
+	}
+	w.rollback()
+
+	got := readTestFiles(t, actionDir)
+	if got["main.sh"].content != "old" {
+		t.Errorf("main.sh = %q, want %q", got["main.sh"].content, "old")
+	}
+	entries, err := os.ReadDir(filepath.Dir(actionDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("unexpected entries next to the action directory: %v", entries)
+	}
+}
+
+// cancelAfter is a context cancelled once it's checked the given number of times
+type cancelAfter struct {
+	context.Context
+	checks int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.checks == 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestFileWriterInterrupted(t *testing.T) {
+	existing := map[string]testFile{
+		"action.yaml": {content: "old", mode: 0600},
+		"notes.txt":   {content: "hand-written", mode: 0644},
+	}
+	files := []*renderedFile{
+		{path: "action.yaml", content: []byte("new")},
+		{path: "main.sh", content: []byte("echo")},
+		{path: "lib/a.sh", content: []byte("a")},
+	}
+
+	// Every file is checked once while staged and once while moved into an existing directory.
+	tests := []struct {
+		name     string
+		existing map[string]testFile
+		checks   int // Checks passed before the interrupt
+	}{
+		{name: "while staging", existing: existing, checks: 1},
+		{name: "while moving files", existing: existing, checks: len(files) + 2},
+		{name: "before moving the new directory", checks: len(files)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			actionDir := filepath.Join(parent, "myaction")
+			if tt.existing != nil {
+				writeTestFiles(t, actionDir, tt.existing)
+			}
+
+			w := newFileWriter()
+			err := w.writeFiles(&cancelAfter{Context: context.Background(), checks: tt.checks}, actionDir, files)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("writeFiles() error = %v, want %v", err, context.Canceled)
+			}
+			w.rollback()
+
+			got := readTestFiles(t, actionDir)
+			if len(got) != len(tt.existing) {
+				t.Errorf("files after rollback = %v, want %v", got, tt.existing)
+			}
+			for name, f := range tt.existing {
+				if got[name].content != f.content {
+					t.Errorf("file %s after rollback = %q, want %q", name, got[name].content, f.content)
+				}
+				// Windows doesn't keep unix permissions.
+				if runtime.GOOS != "windows" && got[name].mode != f.mode {
+					t.Errorf("file %s mode after rollback = %v, want %v", name, got[name].mode, f.mode)
+				}
+			}
+
+			entries, err := os.ReadDir(parent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				if e.Name() != "myaction" {
+					t.Errorf("unexpected entry %s is left next to the action directory", e.Name())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateFuncs are helpers available in templates to render user-entered values safely
+var templateFuncs = template.FuncMap{
+	"yamlQuote":  yamlQuote,
+	"yamlText":   yamlText,
+	"yamlFields": yamlFields,
+	// Go code of the plugin runtime, see gocode.go.
+	"goPackage":      goPackage,
+	"goInputs":       goInputs,
+	"hasSliceInputs": hasSliceInputs,
+}
+
+// yamlQuote renders a value as an inline YAML scalar or flow collection,
+// strings are quoted only when they would be read back differently, e.g. "yes", "0123" or "a: b"
+func yamlQuote(v any) (string, error) {
+	var node yaml.Node
+	err := node.Encode(withFloatNodes(v))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %v to yaml: %w", v, err)
+	}
+
+	setFlowStyle(&node)
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "\n") {
+		// Inline values must stay on one line.
+		node.Style = yaml.DoubleQuotedStyle
+	}
+
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %v to yaml: %w", v, err)
+	}
+
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// yamlText renders a string as a literal block scalar when it spans multiple lines,
+// indent is the indentation of the block content
+func yamlText(indent int, s string) (string, error) {
+	s = strings.TrimRight(s, "\n")
+	if !strings.Contains(s, "\n") {
+		return yamlQuote(s)
+	}
+
+	// Leading spaces of the first line can't be told apart from the indentation.
+	if strings.HasPrefix(s, " ") || strings.HasPrefix(s, "\t") {
+		out, err := json.Marshal(s)
+		return string(out), err
+	}
+
+	pad := strings.Repeat(" ", indent)
+	var b strings.Builder
+	b.WriteString("|-")
+	for _, line := range strings.Split(s, "\n") {
+		b.WriteString("\n")
+		if line != "" {
+			b.WriteString(pad + line)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// setFlowStyle renders collections on a single line
+func setFlowStyle(node *yaml.Node) {
+	if node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
+		node.Style = yaml.FlowStyle
+	}
+	for _, n := range node.Content {
+		setFlowStyle(n)
+	}
+}
+
+// withFloatNodes replaces floats in a value with nodes keeping them floats when they are read back,
+// the encoder writes 3.0 as 3 otherwise
+func withFloatNodes(v any) any {
+	switch vv := v.(type) {
+	case float64:
+		s := strconv.FormatFloat(vv, 'f', -1, 64)
+		if !strings.ContainsAny(s, ".eEIN") {
+			s += ".0"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: s}
+	case []any:
+		res := make([]any, len(vv))
+		for i, item := range vv {
+			res[i] = withFloatNodes(item)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// yamlFields renders fields of a struct or a map as mapping lines, lines after the first one are indented.
+// Fields with empty values are skipped.
+func yamlFields(indent int, v any) (string, error) {
+	var node yaml.Node
+	err := node.Encode(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %v to yaml: %w", v, err)
+	}
+	if node.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("failed to encode %v to yaml: mapping expected", v)
+	}
+
+	setFlowStyle(&node)
+	var lines []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if isEmptyNode(node.Content[i+1]) {
+			continue
+		}
+
+		k, err := yaml.Marshal(node.Content[i])
+		if err != nil {
+			return "", err
+		}
+		val, err := yaml.Marshal(node.Content[i+1])
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, strings.TrimSuffix(string(k), "\n")+": "+strings.TrimSuffix(string(val), "\n"))
+	}
+
+	return strings.Join(lines, "\n"+strings.Repeat(" ", indent)), nil
+}
+
+// isEmptyNode checks if a node is null, an empty string or an empty collection
+func isEmptyNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Tag == "!!null" || (node.Tag == "!!str" && node.Value == "")
+	case yaml.SequenceNode, yaml.MappingNode:
+		return len(node.Content) == 0
+	}
+
+	return false
+}
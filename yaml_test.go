@@ -0,0 +1,99 @@
+package scaffold
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYamlQuote(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{name: "plain string", value: "My Action", want: "My Action"},
+		{name: "colon", value: "a: b", want: `'a: b'`},
+		{name: "hash", value: "a #b", want: `'a #b'`},
+		{name: "bool-like", value: "yes", want: `"yes"`},
+		{name: "number-like", value: "0123", want: `"0123"`},
+		{name: "empty", value: "", want: `""`},
+		{name: "leading dash", value: "- item", want: `'- item'`},
+		{name: "template expression", value: "{{ .name }}", want: `'{{ .name }}'`},
+		{name: "multi-line", value: "first\nsecond", want: `"first\nsecond"`},
+		{name: "integer", value: 3, want: "3"},
+		{name: "whole float", value: 3.0, want: "3.0"},
+		{name: "float", value: 0.5, want: "0.5"},
+		{name: "bool", value: true, want: "true"},
+		{name: "list", value: []any{"a", "b: c", 1, 2.0}, want: `[a, 'b: c', 1, 2.0]`},
+		{name: "empty list", value: []any{}, want: "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlQuote(tt.value)
+			if err != nil {
+				t.Fatalf("yamlQuote(%#v) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("yamlQuote(%#v) = %s, want %s", tt.value, got, tt.want)
+			}
+
+			// The value is read back unchanged.
+			var doc map[string]any
+			err = yaml.Unmarshal([]byte("key: "+got), &doc)
+			if err != nil {
+				t.Fatalf("failed to read back %s: %v", got, err)
+			}
+			if !reflect.DeepEqual(doc["key"], tt.value) {
+				t.Errorf("read back %#v, want %#v", doc["key"], tt.value)
+			}
+		})
+	}
+}
+
+func TestYamlText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string // Expected value read back, trailing newlines are dropped
+	}{
+		{name: "single line", value: "Run: the action", want: "Run: the action"},
+		{name: "multi-line", value: "First line.\nSecond: line # not a comment", want: "First line.\nSecond: line # not a comment"},
+		{name: "empty lines", value: "First\n\n  indented\nlast\n\n", want: "First\n\n  indented\nlast"},
+		{name: "leading spaces", value: "  indented\nsecond", want: "  indented\nsecond"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlText(4, tt.value)
+			if err != nil {
+				t.Fatalf("yamlText() error = %v", err)
+			}
+
+			// The indentation is the one of the action description in the definition template.
+			doc := "action:\n  description: " + got + "\n  title: after\n"
+			var v struct {
+				Action struct {
+					Description string `yaml:"description"`
+					Title       string `yaml:"title"`
+				} `yaml:"action"`
+			}
+			err = yaml.Unmarshal([]byte(doc), &v)
+			if err != nil {
+				t.Fatalf("failed to read back:\n%s\nerror: %v", doc, err)
+			}
+			if v.Action.Description != tt.want {
+				t.Errorf("read back %q, want %q", v.Action.Description, tt.want)
+			}
+			if v.Action.Title != "after" {
+				t.Errorf("text broke the following keys:\n%s", doc)
+			}
+			if strings.Contains(tt.want, "\n") && !strings.HasPrefix(got, "|-") && !strings.HasPrefix(got, `"`) {
+				t.Errorf("multi-line text %q is not a block or a quoted string", got)
+			}
+		})
+	}
+}